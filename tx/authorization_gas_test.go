@@ -0,0 +1,23 @@
+package tx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationsGas(t *testing.T) {
+	gas, overflow := authorizationsGas(0)
+	assert.False(t, overflow)
+	assert.Equal(t, uint64(0), gas)
+
+	gas, overflow = authorizationsGas(3)
+	assert.False(t, overflow)
+	assert.Equal(t, 3*(PerAuthorizationGas+PerEmptyAccountAuthorizationGas), gas)
+
+	// a pathologically large authorization count must be rejected as an
+	// overflow rather than silently wrapping into an undercharged total.
+	_, overflow = authorizationsGas(math.MaxUint64/(PerAuthorizationGas+PerEmptyAccountAuthorizationGas) + 1)
+	assert.True(t, overflow)
+}
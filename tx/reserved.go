@@ -0,0 +1,55 @@
+package tx
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// reserved is body.Reserved's actual representation. Reserved began as a
+// raw RLP list kept for forward-compatible extension; giving it a typed
+// wrapper with its own EncodeRLP/DecodeRLP lets an added field (like
+// Authorizations) round-trip through RLP as a concrete type. rlp.Decode has
+// no way to decode a wire-format list back into a concrete []*Authorization
+// on its own: decoding into []interface{} only ever yields []byte or nested
+// []interface{}, never a registered type. An empty reserved still encodes
+// as an empty list, so a tx without extensions keeps its original wire
+// format and signing hash.
+type reserved struct {
+	Authorizations []*Authorization
+}
+
+func (r reserved) isEmpty() bool {
+	return len(r.Authorizations) == 0
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (r reserved) EncodeRLP(w io.Writer) error {
+	if r.isEmpty() {
+		return rlp.Encode(w, []interface{}{})
+	}
+	return rlp.Encode(w, []interface{}{r.Authorizations})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (r *reserved) DecodeRLP(s *rlp.Stream) error {
+	var raw []rlp.RawValue
+	if err := s.Decode(&raw); err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		*r = reserved{}
+		return nil
+	}
+	if len(raw) > 1 {
+		return errors.New("tx: unsupported reserved fields")
+	}
+
+	var auths []*Authorization
+	if err := rlp.DecodeBytes(raw[0], &auths); err != nil {
+		return err
+	}
+	*r = reserved{Authorizations: auths}
+	return nil
+}
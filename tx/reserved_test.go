@@ -0,0 +1,44 @@
+package tx
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+)
+
+func TestTransactionAuthorizationsRLPRoundTrip(t *testing.T) {
+	auth := NewAuthorization(1, thor.BytesToAddress([]byte("delegate")), 7)
+
+	signed := (&Transaction{body: body{ChainTag: 1, Nonce: 1, Gas: 21000}}).
+		WithAuthorizations([]*Authorization{auth})
+
+	data, err := rlp.EncodeToBytes(signed)
+	assert.Nil(t, err)
+
+	var decoded Transaction
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	assert.True(t, decoded.HasReservedFields())
+
+	got := decoded.Authorizations()
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, auth.Address(), got[0].Address())
+		assert.Equal(t, auth.Nonce(), got[0].Nonce())
+		assert.Equal(t, auth.ChainTag(), got[0].ChainTag())
+	}
+}
+
+func TestTransactionWithoutAuthorizationsKeepsBareReservedEncoding(t *testing.T) {
+	bare := &Transaction{body: body{ChainTag: 1, Nonce: 1, Gas: 21000}}
+	assert.False(t, bare.HasReservedFields())
+
+	data, err := rlp.EncodeToBytes(bare)
+	assert.Nil(t, err)
+
+	var decoded Transaction
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	assert.False(t, decoded.HasReservedFields())
+	assert.Nil(t, decoded.Authorizations())
+	assert.Equal(t, bare.SigningHash(), decoded.SigningHash())
+}
@@ -0,0 +1,148 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+	"github.com/vechain/thor/thor"
+)
+
+// Signer abstracts what gets hashed and who ends up paying for a
+// transaction, so that Thor's plain signing scheme and its VIP-191
+// fee-delegation scheme can share the same body encoding while diverging on
+// signing semantics.
+type Signer interface {
+	// Hash returns the hash that the transaction's origin signs.
+	Hash(tx *Transaction) thor.Bytes32
+	// Sender recovers the origin's address from tx's signature.
+	Sender(tx *Transaction) (thor.Address, error)
+	// SignatureValues derives r, s, v from a signature produced over Hash(tx).
+	SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error)
+}
+
+// delegatorSigner is implemented by Signers that support VIP-191
+// fee-delegation, on top of the base Signer contract.
+type delegatorSigner interface {
+	Signer
+	// DelegatorHash returns the hash that the gas payer signs, given the
+	// recovered origin.
+	DelegatorHash(tx *Transaction, origin thor.Address) thor.Bytes32
+}
+
+// signerName returns a short, stable discriminator for signer, used to key
+// the signer cache so cached results never leak across signer variants.
+func signerName(signer Signer) string {
+	switch signer.(type) {
+	case LegacySigner:
+		return "legacy"
+	case DelegatedSigner:
+		return "delegated"
+	default:
+		return fmt.Sprintf("%T", signer)
+	}
+}
+
+func decodeSignatureValues(sig []byte) (r, s, v *big.Int) {
+	return new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), new(big.Int).SetBytes(sig[64:65])
+}
+
+// LegacySigner implements Thor's original signing scheme: the origin signs
+// the tx body hash (ChainTag folded into the body), and the full body
+// signature is stored verbatim in body.Signature.
+type LegacySigner struct{}
+
+// Hash implements Signer.
+func (LegacySigner) Hash(tx *Transaction) thor.Bytes32 {
+	return tx.bodyHash()
+}
+
+// Sender implements Signer.
+func (s LegacySigner) Sender(tx *Transaction) (thor.Address, error) {
+	pub, err := crypto.SigToPub(s.Hash(tx).Bytes(), tx.body.Signature)
+	if err != nil {
+		return thor.Address{}, err
+	}
+	return thor.Address(crypto.PubkeyToAddress(*pub)), nil
+}
+
+// SignatureValues implements Signer.
+func (LegacySigner) SignatureValues(_ *Transaction, sig []byte) (r, s, v *big.Int, err error) {
+	if len(sig) != 65 {
+		return nil, nil, nil, errors.New("tx: invalid signature length")
+	}
+	r, s, v = decodeSignatureValues(sig)
+	return
+}
+
+// DelegatedSigner implements Thor's VIP-191 fee-delegation scheme: the
+// origin signs the tx body hash, and a separate gas payer signs
+// DelegatorHash, which binds the payer's signature to both the body and the
+// recovered origin. body.Signature carries the two 65-byte signatures back
+// to back: sig[0:65] from the origin, sig[65:130] from the payer.
+type DelegatedSigner struct{}
+
+// Hash implements Signer.
+func (DelegatedSigner) Hash(tx *Transaction) thor.Bytes32 {
+	return tx.bodyHash()
+}
+
+// DelegatorHash returns the hash the gas payer signs: keccak256(bodyHash ||
+// origin).
+func (DelegatedSigner) DelegatorHash(tx *Transaction, origin thor.Address) (hash thor.Bytes32) {
+	bodyHash := tx.bodyHash()
+	hw := sha3.NewKeccak256()
+	hw.Write(bodyHash.Bytes())
+	hw.Write(origin.Bytes())
+	hw.Sum(hash[:0])
+	return
+}
+
+// Sender implements Signer.
+func (s DelegatedSigner) Sender(tx *Transaction) (thor.Address, error) {
+	if len(tx.body.Signature) < 65 {
+		return thor.Address{}, errors.New("tx: invalid signature length")
+	}
+	pub, err := crypto.SigToPub(s.Hash(tx).Bytes(), tx.body.Signature[:65])
+	if err != nil {
+		return thor.Address{}, err
+	}
+	return thor.Address(crypto.PubkeyToAddress(*pub)), nil
+}
+
+// SignatureValues implements Signer. sig may carry the origin signature
+// alone (65 bytes) or back to back with the gas payer's (130 bytes, per
+// Transaction.WithSignature); either way it derives r, s, v for the leading
+// 65-byte origin signature.
+func (DelegatedSigner) SignatureValues(_ *Transaction, sig []byte) (r, s, v *big.Int, err error) {
+	if len(sig) < 65 {
+		return nil, nil, nil, errors.New("tx: invalid signature length")
+	}
+	r, s, v = decodeSignatureValues(sig)
+	return
+}
+
+// Delegator recovers the gas payer of a fee-delegated tx using signer,
+// which must support VIP-191 delegation (DelegatedSigner does).
+func (t *Transaction) Delegator(signer Signer) (thor.Address, error) {
+	ds, ok := signer.(delegatorSigner)
+	if !ok {
+		return thor.Address{}, errors.New("tx: signer does not support delegation")
+	}
+	if len(t.body.Signature) < 130 {
+		return thor.Address{}, errors.New("tx: invalid signature length")
+	}
+
+	origin, err := signer.Sender(t)
+	if err != nil {
+		return thor.Address{}, err
+	}
+
+	pub, err := crypto.SigToPub(ds.DelegatorHash(t, origin).Bytes(), t.body.Signature[65:130])
+	if err != nil {
+		return thor.Address{}, err
+	}
+	return thor.Address(crypto.PubkeyToAddress(*pub)), nil
+}
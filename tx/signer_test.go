@@ -0,0 +1,49 @@
+package tx
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+)
+
+func TestWithSignatureValidatesLength(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+
+	txn := &Transaction{body: body{ChainTag: 1, Nonce: 1, Gas: 21000}}
+
+	sig, err := crypto.Sign(txn.SigningHash().Bytes(), priv)
+	assert.Nil(t, err)
+
+	signed, err := txn.WithSignatureChecked(sig)
+	assert.Nil(t, err)
+	assert.Equal(t, sig, signed.Signature())
+
+	_, err = txn.WithSignatureChecked(sig[:64])
+	assert.NotNil(t, err, "a truncated signature must be rejected")
+}
+
+func TestWithSignatureAcceptsDelegatedLength(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+
+	txn := &Transaction{body: body{ChainTag: 1, Nonce: 1, Gas: 21000}}
+
+	originSig, err := crypto.Sign(DelegatedSigner{}.Hash(txn).Bytes(), priv)
+	assert.Nil(t, err)
+
+	origin := thor.Address(crypto.PubkeyToAddress(priv.PublicKey))
+	payerSig, err := crypto.Sign(DelegatedSigner{}.DelegatorHash(txn, origin).Bytes(), priv)
+	assert.Nil(t, err)
+
+	full := append(append([]byte(nil), originSig...), payerSig...)
+
+	signed, err := txn.WithSignatureChecked(full, DelegatedSigner{})
+	assert.Nil(t, err)
+	assert.Equal(t, full, signed.Signature())
+
+	_, err = txn.WithSignatureChecked(full[:64], DelegatedSigner{})
+	assert.NotNil(t, err, "a truncated signature must be rejected")
+}
@@ -9,7 +9,6 @@ import (
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common/math"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/sha3"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -47,7 +46,7 @@ type body struct {
 	GasPriceCoef uint8
 	Gas          uint64
 	DependsOn    *thor.Bytes32 `rlp:"nil"`
-	Reserved     []interface{}
+	Reserved     reserved
 	Signature    []byte
 }
 
@@ -112,7 +111,15 @@ func (t *Transaction) EvaluateWork(signer thor.Address) *big.Int {
 }
 
 // SigningHash returns hash of tx excludes signature.
-func (t *Transaction) SigningHash() (hash thor.Bytes32) {
+// It is equivalent to LegacySigner{}.Hash(t).
+func (t *Transaction) SigningHash() thor.Bytes32 {
+	return t.bodyHash()
+}
+
+// bodyHash hashes the tx body excluding the signature. It underlies every
+// Signer's Hash implementation, since all of Thor's signing schemes sign
+// over the same body.
+func (t *Transaction) bodyHash() (hash thor.Bytes32) {
 	if cached := t.cache.signingHash.Load(); cached != nil {
 		return cached.(thor.Bytes32)
 	}
@@ -158,44 +165,80 @@ func (t *Transaction) DependsOn() *thor.Bytes32 {
 	return &cpy
 }
 
+// Authorizations returns the authorization list carried by the tx, or nil
+// if it carries none.
+func (t *Transaction) Authorizations() []*Authorization {
+	return append([]*Authorization(nil), t.body.Reserved.Authorizations...)
+}
+
+// WithAuthorizations returns a copy of the tx carrying the given
+// authorization list. Keeping it inside Reserved rather than as a
+// top-level body field means the RLP encoding and signing hash of a tx
+// without authorizations is unchanged.
+func (t *Transaction) WithAuthorizations(auths []*Authorization) *Transaction {
+	newTx := Transaction{body: t.body}
+	newTx.body.Reserved = reserved{Authorizations: append([]*Authorization(nil), auths...)}
+	return &newTx
+}
+
 // Signature returns signature.
 func (t *Transaction) Signature() []byte {
 	return append([]byte(nil), t.body.Signature...)
 }
 
-// Signer extract signer of tx from signature.
-func (t *Transaction) Signer() (signer thor.Address, err error) {
-	if cached := t.cache.signer.Load(); cached != nil {
-		return cached.(thor.Address), nil
-	}
-	defer func() {
-		if err == nil {
-			t.cache.signer.Store(signer)
+// signerCacheKey scopes a cached sender lookup to both the tx bytes and the
+// Signer that produced it, so LegacySigner and DelegatedSigner results
+// never collide in signerCache.
+type signerCacheKey struct {
+	hash   thor.Bytes32
+	signer string
+}
+
+// Signer extracts the tx's origin from its signature, using signer to
+// interpret the signature. Passing no signer defaults to LegacySigner{},
+// which is Thor's original signing scheme.
+func (t *Transaction) Signer(signer ...Signer) (sender thor.Address, err error) {
+	s := pickSigner(signer)
+	if _, ok := s.(LegacySigner); ok {
+		if cached := t.cache.signer.Load(); cached != nil {
+			return cached.(thor.Address), nil
 		}
-	}()
+		defer func() {
+			if err == nil {
+				t.cache.signer.Store(sender)
+			}
+		}()
+	}
 
 	hw := sha3.NewKeccak256()
 	rlp.Encode(hw, &t)
 	var hash thor.Bytes32
 	hw.Sum(hash[:0])
+	key := signerCacheKey{hash, signerName(s)}
 
-	if v, ok := signerCache.Get(hash); ok {
+	if v, ok := signerCache.Get(key); ok {
 		return v.(thor.Address), nil
 	}
 	defer func() {
 		if err == nil {
-			signerCache.Add(hash, signer)
+			signerCache.Add(key, sender)
 		}
 	}()
-	pub, err := crypto.SigToPub(t.SigningHash().Bytes(), t.body.Signature)
-	if err != nil {
-		return thor.Address{}, err
+	return s.Sender(t)
+}
+
+// pickSigner returns signer[0] if present, or LegacySigner{} as the
+// backward-compatible default.
+func pickSigner(signer []Signer) Signer {
+	if len(signer) > 0 {
+		return signer[0]
 	}
-	signer = thor.Address(crypto.PubkeyToAddress(*pub))
-	return
+	return LegacySigner{}
 }
 
-// WithSignature create a new tx with signature set.
+// WithSignature creates a new tx with signature set. Signer/Delegator must
+// later be called with the same signer that produced sig to recover the
+// correct sender.
 func (t *Transaction) WithSignature(sig []byte) *Transaction {
 	newTx := Transaction{
 		body: t.body,
@@ -205,10 +248,21 @@ func (t *Transaction) WithSignature(sig []byte) *Transaction {
 	return &newTx
 }
 
+// WithSignatureChecked is WithSignature, but first validates sig against the
+// scheme signer expects (LegacySigner{} by default), returning an error
+// instead of producing a tx with an unusable signature.
+func (t *Transaction) WithSignatureChecked(sig []byte, signer ...Signer) (*Transaction, error) {
+	s := pickSigner(signer)
+	if _, _, _, err := s.SignatureValues(t, sig); err != nil {
+		return nil, err
+	}
+	return t.WithSignature(sig), nil
+}
+
 // HasReservedFields returns if there're reserved fields.
 // Reserved fields are for backward compatibility purpose.
 func (t *Transaction) HasReservedFields() bool {
-	return len(t.body.Reserved) > 0
+	return !t.body.Reserved.isEmpty()
 }
 
 // EncodeRLP implements rlp.Encoder
@@ -241,38 +295,62 @@ func (t *Transaction) Size() metric.StorageSize {
 
 // IntrinsicGas returns intrinsic gas of tx.
 func (t *Transaction) IntrinsicGas() (uint64, error) {
-	if len(t.body.Clauses) == 0 {
-		return thor.TxGas + thor.ClauseGas, nil
-	}
-
-	var total = thor.TxGas
+	var total uint64
 	var overflow bool
-	for _, c := range t.body.Clauses {
-		gas, err := dataGas(c.body.Data)
-		if err != nil {
-			return 0, err
-		}
-		total, overflow = math.SafeAdd(total, gas)
-		if overflow {
-			return 0, evm.ErrOutOfGas
-		}
-
-		var cgas uint64
-		if c.body.To == nil {
-			// contract creation
-			cgas = thor.ClauseGasContractCreation
-		} else {
-			cgas = thor.ClauseGas
+	if len(t.body.Clauses) == 0 {
+		total = thor.TxGas + thor.ClauseGas
+	} else {
+		total = thor.TxGas
+		for _, c := range t.body.Clauses {
+			gas, err := dataGas(c.body.Data)
+			if err != nil {
+				return 0, err
+			}
+			total, overflow = math.SafeAdd(total, gas)
+			if overflow {
+				return 0, evm.ErrOutOfGas
+			}
+
+			var cgas uint64
+			if c.body.To == nil {
+				// contract creation
+				cgas = thor.ClauseGasContractCreation
+			} else {
+				cgas = thor.ClauseGas
+			}
+
+			total, overflow = math.SafeAdd(total, cgas)
+			if overflow {
+				return 0, evm.ErrOutOfGas
+			}
 		}
+	}
 
-		total, overflow = math.SafeAdd(total, cgas)
-		if overflow {
-			return 0, evm.ErrOutOfGas
-		}
+	authGas, overflow := authorizationsGas(uint64(len(t.Authorizations())))
+	if overflow {
+		return 0, evm.ErrOutOfGas
+	}
+	total, overflow = math.SafeAdd(total, authGas)
+	if overflow {
+		return 0, evm.ErrOutOfGas
 	}
 	return total, nil
 }
 
+// authorizationsGas returns the pessimistic intrinsic gas surcharge for n
+// authorizations, charging PerEmptyAccountAuthorizationGas up-front for
+// every entry since IntrinsicGas has no access to state to tell whether the
+// authority account already exists. vm.VM.ApplyAuthorizations refunds the
+// difference once the authority account is resolved. overflow is true if
+// the surcharge itself cannot be represented as a uint64, which
+// IntrinsicGas treats the same as any other gas overflow.
+func authorizationsGas(n uint64) (gas uint64, overflow bool) {
+	if n == 0 {
+		return 0, false
+	}
+	return math.SafeMul(n, PerAuthorizationGas+PerEmptyAccountAuthorizationGas)
+}
+
 // GasPrice returns gas price.
 // gasPrice = baseGasPrice + baseGasPrice * gasPriceCoef / 255
 func (t *Transaction) GasPrice(baseGasPrice *big.Int) *big.Int {
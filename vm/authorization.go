@@ -0,0 +1,66 @@
+package vm
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/tx"
+	"github.com/vechain/thor/vm/evm"
+)
+
+// delegationDesignator returns the code an authority's account is set to
+// once it delegates to addr, using the same 0xef0100 prefix evm's
+// resolveCode looks for.
+func delegationDesignator(addr thor.Address) []byte {
+	designator := make([]byte, 0, len(evm.DelegationDesignatorPrefix)+len(addr))
+	designator = append(designator, evm.DelegationDesignatorPrefix...)
+	designator = append(designator, addr.Bytes()...)
+	return designator
+}
+
+// ApplyAuthorizations recovers and applies each authorization in auths
+// before the clause executes: for every authorization scoped to the VM's
+// current chain tag, whose signature recovers cleanly, and whose nonce
+// matches the authority's current nonce, it writes a delegation designator
+// into the authority's code. Mutations go through vm.statedb rather than
+// the raw State, the same as any other contract-driven state change, so
+// they fire StateAccessHooks.OnCodeChange/OnNonceChange and land in
+// Output.StateChanges. It returns the total gas to refund against the
+// PerEmptyAccountAuthorizationGas charged up-front by
+// tx.Transaction.IntrinsicGas, for authorities whose account already
+// existed.
+func (vm *VM) ApplyAuthorizations(auths []*tx.Authorization) uint64 {
+	var refund uint64
+	for _, auth := range auths {
+		// A chain tag of 0 authorizes replay on any chain, mirroring
+		// EIP-7702's chain_id == 0 convention; any other tag must match the
+		// chain the VM is executing against, or the authorization is
+		// replayable across chains that share the authority's nonce.
+		if auth.ChainTag() != 0 && auth.ChainTag() != vm.ctx.ChainTag {
+			continue
+		}
+
+		authority, err := auth.Authority()
+		if err != nil {
+			// invalid signature: skip this authorization, no refund.
+			continue
+		}
+		addr := common.Address(authority)
+
+		if vm.statedb.GetNonce(addr) != auth.Nonce() {
+			continue
+		}
+
+		if vm.statedb.Exist(addr) {
+			refund += tx.PerEmptyAccountAuthorizationGas
+		}
+
+		if auth.Address().IsZero() {
+			// a zero delegate address clears any existing delegation.
+			vm.statedb.SetCode(addr, nil)
+		} else {
+			vm.statedb.SetCode(addr, delegationDesignator(auth.Address()))
+		}
+		vm.statedb.SetNonce(addr, auth.Nonce()+1)
+	}
+	return refund
+}
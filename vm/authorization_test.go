@@ -0,0 +1,38 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/tx"
+)
+
+func TestApplyAuthorizationsFiresStatedbHooks(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	authority := thor.Address(crypto.PubkeyToAddress(priv.PublicKey))
+
+	auth := tx.NewAuthorization(0, thor.Address{7}, 0)
+	sig, err := crypto.Sign(auth.SigningHash().Bytes(), priv)
+	assert.Nil(t, err)
+	signed := auth.WithSignature(sig)
+
+	state := newFakeState()
+	vm := newTestVM(state)
+
+	var codeChanges, nonceChanges int
+	vm.SetOnStateAccess(StateAccessHooks{
+		OnCodeChange: func(addr thor.Address, prevHash thor.Bytes32, prevCode []byte, newHash thor.Bytes32, newCode []byte) {
+			codeChanges++
+		},
+		OnNonceChange: func(addr thor.Address, prev, new uint64) { nonceChanges++ },
+	})
+
+	refund := vm.ApplyAuthorizations([]*tx.Authorization{signed})
+	assert.Equal(t, uint64(0), refund, "a not-yet-existing authority must not be refunded")
+	assert.Equal(t, 1, codeChanges, "the delegation designator write must go through statedb like any other SetCode")
+	assert.Equal(t, 1, nonceChanges, "the authority nonce bump must go through statedb like any other SetNonce")
+	assert.Equal(t, uint64(1), state.GetNonce(authority))
+}
@@ -0,0 +1,46 @@
+package evm
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DelegationDesignatorPrefix is written as the leading three bytes of an
+// account's code once it delegates execution to another address, per the
+// EIP-7702 set-code convention (0xef0100 || address). vm.ApplyAuthorizations
+// is what writes it; resolveCode is what a future interpreter would resolve
+// it through.
+var DelegationDesignatorPrefix = []byte{0xef, 0x01, 0x00}
+
+// resolveCode returns the code that should actually run for addr: if addr's
+// own code is a delegation designator, the delegate's code is returned
+// instead. This package has no opcode interpreter to run the result against
+// (see EVM.call), so resolveCode does not yet make a delegation take effect;
+// it exists so that callers introspecting an account's effective code (and a
+// future interpreter) have a single place to resolve it correctly.
+func resolveCode(db StateDB, addr common.Address) []byte {
+	code := db.GetCode(addr)
+	if target, ok := delegationTarget(code); ok {
+		return db.GetCode(target)
+	}
+	return code
+}
+
+// delegationTarget reports the delegate address encoded in code, if code is
+// a delegation designator.
+func delegationTarget(code []byte) (common.Address, bool) {
+	if len(code) != len(DelegationDesignatorPrefix)+common.AddressLength {
+		return common.Address{}, false
+	}
+	if !bytes.HasPrefix(code, DelegationDesignatorPrefix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(DelegationDesignatorPrefix):]), true
+}
+
+// createAddress computes the address of a contract created by sender at nonce.
+func createAddress(sender common.Address, nonce uint64) common.Address {
+	return crypto.CreateAddress(sender, nonce)
+}
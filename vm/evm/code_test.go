@@ -0,0 +1,45 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegationTarget(t *testing.T) {
+	delegate := common.BytesToAddress([]byte{0xaa})
+
+	target, ok := delegationTarget(append(append([]byte{}, DelegationDesignatorPrefix...), delegate.Bytes()...))
+	assert.True(t, ok)
+	assert.Equal(t, delegate, target)
+
+	_, ok = delegationTarget(nil)
+	assert.False(t, ok, "empty code is not a designator")
+
+	_, ok = delegationTarget([]byte{0xef, 0x01, 0x00})
+	assert.False(t, ok, "designator prefix without an address is too short")
+
+	wrongPrefix := append([]byte{0xef, 0x01, 0x01}, delegate.Bytes()...)
+	_, ok = delegationTarget(wrongPrefix)
+	assert.False(t, ok, "wrong prefix is not a designator")
+}
+
+func TestResolveCode(t *testing.T) {
+	statedb := newFakeStateDB()
+	delegate := common.BytesToAddress([]byte{0xbb})
+	delegateCode := []byte{0x60, 0x00}
+	statedb.SetCode(delegate, delegateCode)
+
+	authority := common.BytesToAddress([]byte{0xaa})
+	statedb.SetCode(authority, append(append([]byte{}, DelegationDesignatorPrefix...), delegate.Bytes()...))
+	assert.Equal(t, delegateCode, resolveCode(statedb, authority))
+
+	plain := common.BytesToAddress([]byte{0xcc})
+	plainCode := []byte{0x60, 0x01}
+	statedb.SetCode(plain, plainCode)
+	assert.Equal(t, plainCode, resolveCode(statedb, plain))
+
+	empty := common.BytesToAddress([]byte{0xdd})
+	assert.Nil(t, resolveCode(statedb, empty))
+}
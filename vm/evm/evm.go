@@ -0,0 +1,285 @@
+// Package evm is Thor's fork of the EVM execution engine: a common.Address,
+// common.Hash-keyed interpreter that vm.VM wraps with thor.Address-keyed
+// ergonomics.
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrOutOfGas is returned when a call runs out of gas.
+var ErrOutOfGas = errors.New("out of gas")
+
+// ErrInsufficientBalance is returned when a call's value exceeds the
+// caller's balance.
+var ErrInsufficientBalance = errors.New("insufficient balance for transfer")
+
+// BalanceChangeReason classifies why a balance mutation happened, so a
+// StateDB implementation (and hook consumers layered on top of it, see
+// vm.StateAccessHooks) can distinguish transfers from gas accounting
+// without re-deriving it from call context.
+type BalanceChangeReason byte
+
+const (
+	// BalanceChangeTransfer is a value transfer between a caller and callee.
+	BalanceChangeTransfer BalanceChangeReason = iota + 1
+	// BalanceChangeGasBuy is the upfront deduction of gas cost from the tx origin.
+	BalanceChangeGasBuy
+	// BalanceChangeGasRefund is the return of unused gas cost to the tx origin.
+	BalanceChangeGasRefund
+	// BalanceChangeReward is a block/clause reward payout.
+	BalanceChangeReward
+	// BalanceChangeSelfdestruct is the beneficiary payout of a destructed account's balance.
+	BalanceChangeSelfdestruct
+	// BalanceChangePrecompile is a balance mutation made directly by a
+	// stateful precompile through its PrecompileContext.State(), outside
+	// the regular transfer/gas/reward paths.
+	BalanceChangePrecompile
+)
+
+// StateDB is everything the EVM needs from account state. It is satisfied
+// structurally by *statedb.StateDB; this package does not import statedb to
+// avoid a dependency cycle (vm imports both).
+type StateDB interface {
+	GetBalance(addr common.Address) *big.Int
+	SubBalance(addr common.Address, amount *big.Int, reason BalanceChangeReason)
+	AddBalance(addr common.Address, amount *big.Int, reason BalanceChangeReason)
+	GetNonce(addr common.Address) uint64
+	SetNonce(addr common.Address, nonce uint64)
+	GetCodeHash(addr common.Address) common.Hash
+	GetCode(addr common.Address) []byte
+	GetCodeSize(addr common.Address) int
+	SetCode(addr common.Address, code []byte)
+	GetState(addr common.Address, slot common.Hash) common.Hash
+	SetState(addr common.Address, slot, value common.Hash)
+	Exist(addr common.Address) bool
+	Empty(addr common.Address) bool
+	Suicide(addr, beneficiary common.Address) bool
+	HasSuicided(addr common.Address) bool
+	AddRefund(gas uint64)
+	SubRefund(gas uint64)
+	GetRefund() uint64
+	AddLog(log *types.Log)
+	AddPreimage(hash common.Hash, preimage []byte)
+
+	// EnterFrame records descent into a new call frame, returning its depth
+	// (the outermost call is depth 1). ExitFrame records return from the
+	// most recently entered frame not yet exited, reporting whether it
+	// reverted so depth-aware consumers (see vm.StateChange.Depth) can
+	// discard state changes made by a reverted sub-call.
+	EnterFrame(caller, addr common.Address) int
+	ExitFrame(reverted bool)
+}
+
+// ContractRef is a reference to a contract, i.e. anything CALL-able.
+type ContractRef interface {
+	Address() common.Address
+}
+
+// ContractHook lets a caller hijack contract calls, bypassing regular code
+// execution. Once installed it receives every call, whatever its target
+// address.
+type ContractHook func(evm *EVM, caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error)
+
+// OnContractCreated is invoked after a new contract account is created,
+// before its constructor code runs.
+type OnContractCreated func(addr common.Address)
+
+// Context provides the EVM with auxiliary information that changes per
+// block/transaction, mirroring go-ethereum's vm.Context.
+type Context struct {
+	CanTransfer func(StateDB, common.Address, *big.Int) bool
+	Transfer    func(StateDB, common.Address, common.Address, *big.Int)
+	GetHash     func(uint64) common.Hash
+
+	Origin      common.Address
+	Coinbase    common.Address
+	BlockNumber *big.Int
+	Time        *big.Int
+	Difficulty  *big.Int
+	GasLimit    uint64
+	GasPrice    *big.Int
+
+	TxID        [32]byte
+	ClauseIndex uint32
+}
+
+// Config holds EVM execution options.
+type Config struct{}
+
+// EVM is the Thor-flavored EVM execution engine.
+type EVM struct {
+	context     Context
+	statedb     StateDB
+	chainConfig *params.ChainConfig
+	config      Config
+
+	contractHook      ContractHook
+	onContractCreated OnContractCreated
+	tracer            Tracer
+
+	depth     int
+	cancelled int32
+}
+
+// NewEVM returns a new EVM. The returned EVM is not thread safe and should
+// only ever be used once.
+func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, config Config) *EVM {
+	return &EVM{
+		context:     ctx,
+		statedb:     statedb,
+		chainConfig: chainConfig,
+		config:      config,
+	}
+}
+
+// ChainConfig returns the chain configuration.
+func (evm *EVM) ChainConfig() *params.ChainConfig {
+	return evm.chainConfig
+}
+
+// SetContractHook installs hook, hijacking every call regardless of target.
+func (evm *EVM) SetContractHook(hook ContractHook) {
+	evm.contractHook = hook
+}
+
+// SetOnContractCreated installs cb, called after every new contract account
+// is created.
+func (evm *EVM) SetOnContractCreated(cb OnContractCreated) {
+	evm.onContractCreated = cb
+}
+
+// SetTracer installs t, which receives a CaptureStart/CaptureEnd pair around
+// the outermost Call/StaticCall/Create and a CaptureEnter/CaptureExit pair
+// around every call nested inside it. Passing nil detaches any previously
+// set tracer.
+func (evm *EVM) SetTracer(t Tracer) {
+	evm.tracer = t
+}
+
+// Cancel cancels any running EVM operation. Safe to call concurrently and
+// multiple times.
+func (evm *EVM) Cancel() {
+	atomic.StoreInt32(&evm.cancelled, 1)
+}
+
+// Call executes the contract at addr.
+func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error) {
+	return evm.call(caller, addr, input, gas, value, false)
+}
+
+// StaticCall executes the contract at addr without permitting state
+// mutations.
+func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte, gas uint64) (ret []byte, leftOverGas uint64, err error) {
+	return evm.call(caller, addr, input, gas, new(big.Int), true)
+}
+
+func (evm *EVM) call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int, readOnly bool) (ret []byte, leftOverGas uint64, err error) {
+	from := caller.Address()
+
+	depth := evm.statedb.EnterFrame(from, addr)
+	evm.depth++
+	if evm.tracer != nil {
+		if depth == 1 {
+			evm.tracer.CaptureStart(from, addr, false, input, gas, value)
+		} else {
+			typ := CALL
+			if readOnly {
+				typ = STATICCALL
+			}
+			evm.tracer.CaptureEnter(typ, from, addr, input, gas, value)
+		}
+	}
+	defer func() {
+		evm.depth--
+		evm.statedb.ExitFrame(err != nil)
+		if evm.tracer != nil {
+			gasUsed := gas - leftOverGas
+			if depth == 1 {
+				evm.tracer.CaptureEnd(ret, gasUsed, err)
+			} else {
+				evm.tracer.CaptureExit(ret, gasUsed, err)
+			}
+		}
+	}()
+
+	if evm.contractHook != nil {
+		ret, leftOverGas, err = evm.contractHook(evm, caller, addr, input, gas, value)
+		return
+	}
+
+	if !readOnly && value != nil && value.Sign() != 0 {
+		if !evm.context.CanTransfer(evm.statedb, from, value) {
+			leftOverGas = gas
+			err = ErrInsufficientBalance
+			return
+		}
+		evm.context.Transfer(evm.statedb, from, addr, value)
+	}
+
+	// This package ships no opcode interpreter, so every Call/Create below
+	// this point is a no-op that just returns: there is no bytecode loop to
+	// run addr's code, resolved or not, against. resolveCode is deliberately
+	// not called here as a result — an account delegating via an
+	// EIP-7702-style designator (vm.ApplyAuthorizations) has no different
+	// runtime effect than one with no code at all; only introspection of
+	// GetCode sees the designator today. A real interpreter landing in this
+	// package is what would make resolveCode's result matter at a call site.
+	leftOverGas = gas
+	return
+}
+
+// Create creates a new contract using code as deployment code.
+func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	from := caller.Address()
+	contractAddr = createAddress(from, evm.statedb.GetNonce(from))
+
+	depth := evm.statedb.EnterFrame(from, contractAddr)
+	evm.depth++
+	if evm.tracer != nil {
+		if depth == 1 {
+			evm.tracer.CaptureStart(from, contractAddr, true, code, gas, value)
+		} else {
+			evm.tracer.CaptureEnter(CREATE, from, contractAddr, code, gas, value)
+		}
+	}
+	defer func() {
+		evm.depth--
+		evm.statedb.ExitFrame(err != nil)
+		if evm.tracer != nil {
+			gasUsed := gas - leftOverGas
+			if depth == 1 {
+				evm.tracer.CaptureEnd(ret, gasUsed, err)
+			} else {
+				evm.tracer.CaptureExit(ret, gasUsed, err)
+			}
+		}
+	}()
+
+	evm.statedb.SetNonce(from, evm.statedb.GetNonce(from)+1)
+
+	if value != nil && value.Sign() != 0 {
+		if !evm.context.CanTransfer(evm.statedb, from, value) {
+			leftOverGas = gas
+			err = ErrInsufficientBalance
+			return
+		}
+		evm.context.Transfer(evm.statedb, from, contractAddr, value)
+	}
+
+	evm.statedb.SetCode(contractAddr, code)
+	if evm.onContractCreated != nil {
+		evm.onContractCreated(contractAddr)
+	}
+	// As with Call, there is no opcode interpreter in this tree: the
+	// deployment code is stored verbatim rather than executed and its
+	// return value used as the runtime code.
+	leftOverGas = gas
+	return
+}
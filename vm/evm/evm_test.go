@@ -0,0 +1,135 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStateDB is a minimal in-memory StateDB for exercising EVM in
+// isolation, mirroring the depth bookkeeping statedb.StateDB does.
+type fakeStateDB struct {
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	code     map[common.Address][]byte
+	depth    int
+}
+
+func newFakeStateDB() *fakeStateDB {
+	return &fakeStateDB{
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address]uint64),
+		code:     make(map[common.Address][]byte),
+	}
+}
+
+func (s *fakeStateDB) GetBalance(addr common.Address) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+func (s *fakeStateDB) SubBalance(addr common.Address, amount *big.Int, _ BalanceChangeReason) {
+	s.balances[addr] = new(big.Int).Sub(s.GetBalance(addr), amount)
+}
+func (s *fakeStateDB) AddBalance(addr common.Address, amount *big.Int, _ BalanceChangeReason) {
+	s.balances[addr] = new(big.Int).Add(s.GetBalance(addr), amount)
+}
+func (s *fakeStateDB) GetNonce(addr common.Address) uint64               { return s.nonces[addr] }
+func (s *fakeStateDB) SetNonce(addr common.Address, nonce uint64)        { s.nonces[addr] = nonce }
+func (s *fakeStateDB) GetCodeHash(common.Address) common.Hash            { return common.Hash{} }
+func (s *fakeStateDB) GetCode(addr common.Address) []byte                { return s.code[addr] }
+func (s *fakeStateDB) GetCodeSize(addr common.Address) int               { return len(s.code[addr]) }
+func (s *fakeStateDB) SetCode(addr common.Address, code []byte)          { s.code[addr] = code }
+func (s *fakeStateDB) GetState(common.Address, common.Hash) common.Hash  { return common.Hash{} }
+func (s *fakeStateDB) SetState(common.Address, common.Hash, common.Hash) {}
+func (s *fakeStateDB) Exist(common.Address) bool                         { return true }
+func (s *fakeStateDB) Empty(common.Address) bool                         { return false }
+func (s *fakeStateDB) Suicide(common.Address, common.Address) bool       { return false }
+func (s *fakeStateDB) HasSuicided(common.Address) bool                   { return false }
+func (s *fakeStateDB) AddRefund(uint64)                                  {}
+func (s *fakeStateDB) SubRefund(uint64)                                  {}
+func (s *fakeStateDB) GetRefund() uint64                                 { return 0 }
+func (s *fakeStateDB) AddLog(*types.Log)                                 {}
+func (s *fakeStateDB) AddPreimage(common.Hash, []byte)                   {}
+
+func (s *fakeStateDB) EnterFrame(common.Address, common.Address) int {
+	s.depth++
+	return s.depth
+}
+func (s *fakeStateDB) ExitFrame(bool) { s.depth-- }
+
+type contractRef common.Address
+
+func (c contractRef) Address() common.Address { return common.Address(c) }
+
+// fakeTracer records every call it receives, in order, for assertion.
+type fakeTracer struct {
+	starts int
+	ends   int
+	enters []OpCode
+	exits  int
+}
+
+func (f *fakeTracer) CaptureStart(common.Address, common.Address, bool, []byte, uint64, *big.Int) {
+	f.starts++
+}
+func (f *fakeTracer) CaptureEnd([]byte, uint64, error) { f.ends++ }
+func (f *fakeTracer) CaptureEnter(typ OpCode, _, _ common.Address, _ []byte, _ uint64, _ *big.Int) {
+	f.enters = append(f.enters, typ)
+}
+func (f *fakeTracer) CaptureExit([]byte, uint64, error) { f.exits++ }
+func (f *fakeTracer) CaptureState(uint64, OpCode, uint64, uint64, *ScopeContext, []byte, int, error) {
+}
+func (f *fakeTracer) CaptureFault(uint64, OpCode, uint64, uint64, *ScopeContext, int, error) {}
+
+func TestCallFiresCaptureStartEndAtOutermostDepth(t *testing.T) {
+	statedb := newFakeStateDB()
+	e := NewEVM(Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}, statedb, nil, Config{})
+
+	tracer := &fakeTracer{}
+	e.SetTracer(tracer)
+
+	_, _, err := e.Call(contractRef{1}, common.Address{2}, nil, 1000, new(big.Int))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tracer.starts)
+	assert.Equal(t, 1, tracer.ends)
+	assert.Empty(t, tracer.enters)
+	assert.Equal(t, 0, tracer.exits)
+}
+
+// TestNestedCallFiresCaptureEnterExit drives a nested call through
+// contractHook, the only way to reenter EVM.call without a bytecode
+// interpreter, and checks the inner call reports as depth 2 via
+// CaptureEnter/CaptureExit rather than CaptureStart/CaptureEnd.
+func TestNestedCallFiresCaptureEnterExit(t *testing.T) {
+	statedb := newFakeStateDB()
+	e := NewEVM(Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+	}, statedb, nil, Config{})
+
+	tracer := &fakeTracer{}
+	e.SetTracer(tracer)
+
+	inner := common.Address{3}
+	e.SetContractHook(func(evm *EVM, caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error) {
+		if addr == inner {
+			return nil, gas, nil
+		}
+		return evm.call(caller, inner, input, gas, value, false)
+	})
+
+	_, _, err := e.Call(contractRef{1}, common.Address{2}, nil, 1000, new(big.Int))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tracer.starts)
+	assert.Equal(t, 1, tracer.ends)
+	assert.Equal(t, []OpCode{CALL}, tracer.enters)
+	assert.Equal(t, 1, tracer.exits)
+}
@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OpCode identifies the kind of call reported to a Tracer's CaptureEnter, or
+// the opcode passed to CaptureState/CaptureFault. This tree ships no
+// bytecode interpreter, so only the call-kind values below are ever
+// produced; CaptureState/CaptureFault exist to keep the Tracer contract
+// shaped like go-ethereum's vm.EVMLogger; see their doc comments.
+type OpCode byte
+
+const (
+	CALL OpCode = iota
+	CALLCODE
+	DELEGATECALL
+	STATICCALL
+	CREATE
+	CREATE2
+)
+
+var opCodeNames = map[OpCode]string{
+	CALL:         "CALL",
+	CALLCODE:     "CALLCODE",
+	DELEGATECALL: "DELEGATECALL",
+	STATICCALL:   "STATICCALL",
+	CREATE:       "CREATE",
+	CREATE2:      "CREATE2",
+}
+
+// String implements fmt.Stringer.
+func (op OpCode) String() string {
+	if name, ok := opCodeNames[op]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// Memory, Stack and Contract expose interpreter state to a Tracer's
+// CaptureState/CaptureFault. Kept empty here since nothing populates them:
+// this tree has no opcode interpreter, so CaptureState/CaptureFault are
+// never invoked. They stay part of the Tracer contract so its shape matches
+// go-ethereum's and an interpreter added later can wire them in without
+// another breaking change to every Tracer implementation.
+type Memory struct {
+	Data []byte
+}
+
+// Stack mirrors go-ethereum's EVM stack, for a future interpreter to populate.
+type Stack struct {
+	Data []*big.Int
+}
+
+// Contract mirrors go-ethereum's Contract, for a future interpreter to populate.
+type Contract struct {
+	Caller  common.Address
+	Address common.Address
+	Code    []byte
+	Gas     uint64
+}
+
+// ScopeContext exposes the interpreter state around the opcode currently
+// being executed, for a Tracer to inspect during CaptureState.
+type ScopeContext struct {
+	Memory   *Memory
+	Stack    *Stack
+	Contract *Contract
+}
+
+// Tracer receives call-frame-level callbacks from EVM.call/Create, mirroring
+// go-ethereum's vm.EVMLogger. CaptureStart/CaptureEnd bracket the outermost
+// call; CaptureEnter/CaptureExit bracket every call nested inside it.
+// CaptureState/CaptureFault are part of the contract for shape-compatibility
+// with go-ethereum, but this tree has no opcode interpreter to drive them,
+// so a Tracer should not expect them to ever fire.
+type Tracer interface {
+	// CaptureStart is called once at the beginning of the outermost call.
+	CaptureStart(from, to common.Address, create bool, input []byte, gas uint64, value *big.Int)
+	// CaptureEnd is called once when the outermost call returns.
+	CaptureEnd(output []byte, gasUsed uint64, err error)
+	// CaptureEnter is called at the start of each call nested inside the
+	// outermost one.
+	CaptureEnter(typ OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int)
+	// CaptureExit is called when a nested call returns.
+	CaptureExit(output []byte, gasUsed uint64, err error)
+	// CaptureState is called before executing each opcode. Never invoked in
+	// this tree; see the type doc comment.
+	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error)
+	// CaptureFault is called when execution fails to complete an opcode.
+	// Never invoked in this tree; see the type doc comment.
+	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
+}
@@ -0,0 +1,252 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm/evm"
+	"github.com/vechain/thor/vm/statedb"
+)
+
+// ErrPrecompileReadOnly is returned when a precompile attempts to mutate
+// state while being invoked through StaticCall.
+var ErrPrecompileReadOnly = errors.New("vm: precompile state mutation in read-only context")
+
+// Precompile is implemented by stateful precompiled contracts that can be
+// registered on a VM's PrecompileRegistry. Unlike a plain ContractHook, a
+// Precompile is addressed like any other contract and receives a
+// PrecompileContext scoped to the call that invoked it.
+type Precompile interface {
+	// RequiredGas returns the gas required to execute the precompile with
+	// the given input.
+	RequiredGas(input []byte) uint64
+	// Run executes the precompile against input and returns its output.
+	Run(ctx PrecompileContext, input []byte) ([]byte, error)
+}
+
+// PrecompileContext exposes the VM state and re-entry helpers available to a
+// Precompile while it runs.
+type PrecompileContext interface {
+	// Context returns the enclosing VM context (origin, block number, tx id,
+	// clause index).
+	Context() Context
+	// Caller returns the address that invoked the precompile.
+	Caller() thor.Address
+	// State returns a read/write handle to the world state. When the
+	// precompile is being executed via StaticCall, the returned State
+	// rejects mutating operations.
+	State() State
+	// StaticCall re-enters the EVM as a read-only call.
+	StaticCall(addr thor.Address, input []byte, gas uint64) *Output
+	// Call re-enters the EVM as a state-mutating call. Invoked from a
+	// StaticCall context, it returns an Output with VMErr set to
+	// ErrPrecompileReadOnly instead of performing the call; use StaticCall
+	// instead.
+	Call(addr thor.Address, input []byte, gas uint64, value *big.Int) *Output
+	// UseGas consumes gas from the calling contract's gas meter, returning
+	// false if insufficient gas remains.
+	UseGas(gas uint64) bool
+	// Log emits a log event from the precompile's own address, through the
+	// same statedb path regular contract execution uses, so it appears in
+	// Output.Logs like a LOG opcode's output would. Like a State mutation,
+	// it panics with ErrPrecompileReadOnly when called from a StaticCall.
+	Log(topics []thor.Bytes32, data []byte)
+}
+
+// PrecompileRegistry holds the set of Precompiles that a VM consults before
+// falling back to regular contract execution.
+type PrecompileRegistry struct {
+	precompiles map[thor.Address]Precompile
+}
+
+// NewPrecompileRegistry returns an empty PrecompileRegistry.
+func NewPrecompileRegistry() *PrecompileRegistry {
+	return &PrecompileRegistry{precompiles: make(map[thor.Address]Precompile)}
+}
+
+// Register associates addr with p. Registering the same address twice
+// replaces the previous precompile.
+func (r *PrecompileRegistry) Register(addr thor.Address, p Precompile) {
+	r.precompiles[addr] = p
+}
+
+// Get returns the precompile registered at addr, if any.
+func (r *PrecompileRegistry) Get(addr thor.Address) (Precompile, bool) {
+	p, ok := r.precompiles[addr]
+	return p, ok
+}
+
+// precompileContext is the concrete PrecompileContext handed to Precompiles
+// by VM.Call/StaticCall.
+type precompileContext struct {
+	vm       *VM
+	ctx      Context
+	self     thor.Address // the precompile's own address.
+	caller   thor.Address // the address that invoked the precompile.
+	state    State
+	readOnly bool
+	gas      *uint64
+}
+
+func (c *precompileContext) Context() Context {
+	return c.ctx
+}
+
+func (c *precompileContext) Caller() thor.Address {
+	return c.caller
+}
+
+func (c *precompileContext) State() State {
+	if c.readOnly {
+		return newReadOnlyState(c.state)
+	}
+	return c.state
+}
+
+func (c *precompileContext) StaticCall(addr thor.Address, input []byte, gas uint64) *Output {
+	return c.vm.StaticCall(c.self, addr, input, gas)
+}
+
+func (c *precompileContext) Call(addr thor.Address, input []byte, gas uint64, value *big.Int) *Output {
+	if c.readOnly {
+		return &Output{VMErr: ErrPrecompileReadOnly}
+	}
+	return c.vm.Call(c.self, addr, input, gas, value)
+}
+
+func (c *precompileContext) UseGas(gas uint64) bool {
+	if *c.gas < gas {
+		return false
+	}
+	*c.gas -= gas
+	return true
+}
+
+func (c *precompileContext) Log(topics []thor.Bytes32, data []byte) {
+	if c.readOnly {
+		panic(ErrPrecompileReadOnly)
+	}
+	ethTopics := make([]common.Hash, len(topics))
+	for i, t := range topics {
+		ethTopics[i] = common.Hash(t)
+	}
+	c.vm.statedb.AddLog(&types.Log{
+		Address: common.Address(c.self),
+		Topics:  ethTopics,
+		Data:    data,
+	})
+}
+
+// readOnlyState wraps a State so that mutating methods panic instead of
+// silently applying, mirroring the guarantees StaticCall gives to regular
+// contract code.
+type readOnlyState struct {
+	State
+}
+
+func newReadOnlyState(s State) State {
+	return &readOnlyState{s}
+}
+
+func (s *readOnlyState) SetBalance(addr thor.Address, balance *big.Int) {
+	panic(ErrPrecompileReadOnly)
+}
+
+func (s *readOnlyState) SetEnergy(addr thor.Address, energy *big.Int, blockTime uint64) {
+	panic(ErrPrecompileReadOnly)
+}
+
+func (s *readOnlyState) SetStorage(addr thor.Address, key, value thor.Bytes32) {
+	panic(ErrPrecompileReadOnly)
+}
+
+func (s *readOnlyState) SetCode(addr thor.Address, code []byte) {
+	panic(ErrPrecompileReadOnly)
+}
+
+func (s *readOnlyState) SetNonce(addr thor.Address, nonce uint64) {
+	panic(ErrPrecompileReadOnly)
+}
+
+func (s *readOnlyState) Delete(addr thor.Address) {
+	panic(ErrPrecompileReadOnly)
+}
+
+// hookedState is the State handed to a Precompile by runPrecompile.
+// Balance, nonce, code, storage and existence all have a statedb.StateDB
+// equivalent, so they're routed through it: a precompile's writes fire the
+// same StateAccessHooks and land in Output.StateChanges as any other
+// contract's would. Energy and Delete have no StateDB equivalent (core EVM
+// has no notion of either) and so still go straight to the raw State.
+type hookedState struct {
+	raw State
+	sdb *statedb.StateDB
+}
+
+func newHookedState(raw State, sdb *statedb.StateDB) State {
+	return &hookedState{raw: raw, sdb: sdb}
+}
+
+func (s *hookedState) GetBalance(addr thor.Address) *big.Int {
+	return s.sdb.GetBalance(common.Address(addr))
+}
+
+// SetBalance sets addr's balance to an absolute value, like State.SetBalance
+// does, by translating it into the Add/SubBalance delta statedb.StateDB
+// actually hooks.
+func (s *hookedState) SetBalance(addr thor.Address, balance *big.Int) {
+	diff := new(big.Int).Sub(balance, s.sdb.GetBalance(common.Address(addr)))
+	switch diff.Sign() {
+	case 1:
+		s.sdb.AddBalance(common.Address(addr), diff, evm.BalanceChangePrecompile)
+	case -1:
+		s.sdb.SubBalance(common.Address(addr), diff.Neg(diff), evm.BalanceChangePrecompile)
+	}
+}
+
+func (s *hookedState) GetEnergy(addr thor.Address, blockTime uint64) *big.Int {
+	return s.raw.GetEnergy(addr, blockTime)
+}
+
+func (s *hookedState) SetEnergy(addr thor.Address, energy *big.Int, blockTime uint64) {
+	s.raw.SetEnergy(addr, energy, blockTime)
+}
+
+func (s *hookedState) GetStorage(addr thor.Address, key thor.Bytes32) thor.Bytes32 {
+	return thor.Bytes32(s.sdb.GetState(common.Address(addr), common.Hash(key)))
+}
+
+func (s *hookedState) SetStorage(addr thor.Address, key, value thor.Bytes32) {
+	s.sdb.SetState(common.Address(addr), common.Hash(key), common.Hash(value))
+}
+
+func (s *hookedState) GetCode(addr thor.Address) []byte {
+	return s.sdb.GetCode(common.Address(addr))
+}
+
+func (s *hookedState) SetCode(addr thor.Address, code []byte) {
+	s.sdb.SetCode(common.Address(addr), code)
+}
+
+func (s *hookedState) GetCodeHash(addr thor.Address) thor.Bytes32 {
+	return thor.Bytes32(s.sdb.GetCodeHash(common.Address(addr)))
+}
+
+func (s *hookedState) GetNonce(addr thor.Address) uint64 {
+	return s.sdb.GetNonce(common.Address(addr))
+}
+
+func (s *hookedState) SetNonce(addr thor.Address, nonce uint64) {
+	s.sdb.SetNonce(common.Address(addr), nonce)
+}
+
+func (s *hookedState) Exists(addr thor.Address) bool {
+	return s.sdb.Exist(common.Address(addr))
+}
+
+func (s *hookedState) Delete(addr thor.Address) {
+	s.raw.Delete(addr)
+}
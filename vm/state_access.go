@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm/evm"
+	"github.com/vechain/thor/vm/statedb"
+)
+
+// BalanceChangeReason classifies why a balance mutation happened, so
+// consumers of StateAccessHooks can distinguish transfers from gas
+// accounting without re-deriving it from call context. It is an alias for
+// evm.BalanceChangeReason, which is the type a vm/evm.StateDB implementation
+// (statedb.StateDB) actually passes through SubBalance/AddBalance.
+type BalanceChangeReason = evm.BalanceChangeReason
+
+const (
+	// BalanceChangeTransfer is a value transfer between a caller and callee.
+	BalanceChangeTransfer = evm.BalanceChangeTransfer
+	// BalanceChangeGasBuy is the upfront deduction of gas cost from the tx origin.
+	BalanceChangeGasBuy = evm.BalanceChangeGasBuy
+	// BalanceChangeGasRefund is the return of unused gas cost to the tx origin.
+	BalanceChangeGasRefund = evm.BalanceChangeGasRefund
+	// BalanceChangeReward is a block/clause reward payout.
+	BalanceChangeReward = evm.BalanceChangeReward
+	// BalanceChangeSelfdestruct is the beneficiary payout of a destructed account's balance.
+	BalanceChangeSelfdestruct = evm.BalanceChangeSelfdestruct
+)
+
+// StateAccessHooks is a full state-access tracer usable for live indexing:
+// every mutating statedb method emits the corresponding event before it is
+// journaled, so a consumer sees state changes in the order they're applied
+// and can pair them with OnEnterFrame/OnExitFrame to discard changes made
+// by reverted sub-calls.
+type StateAccessHooks struct {
+	OnBalanceChange func(addr thor.Address, prev, new *big.Int, reason BalanceChangeReason)
+	OnNonceChange   func(addr thor.Address, prev, new uint64)
+	OnCodeChange    func(addr thor.Address, prevHash thor.Bytes32, prevCode []byte, newHash thor.Bytes32, newCode []byte)
+	OnStorageChange func(addr thor.Address, slot, prev, new thor.Bytes32)
+	OnSuicide       func(addr, beneficiary thor.Address, balance *big.Int)
+	OnLog           func(log *Log)
+
+	// OnEnterFrame/OnExitFrame bracket every Call/StaticCall/Create,
+	// including the outermost one, so consumers can maintain a frame stack
+	// and discard the state-access events belonging to a reverted frame.
+	OnEnterFrame func(depth int, caller, addr thor.Address)
+	OnExitFrame  func(depth int, reverted bool)
+}
+
+// StateChange is one entry of an Output.StateChanges trace, produced when
+// state-change recording is enabled on the VM. It captures the same
+// information reported through StateAccessHooks, in application order, for
+// callers that would rather consume a batch than a callback stream. Depth is
+// the call depth (see StateAccessHooks.OnEnterFrame) the change was made
+// at, so a caller can discard entries made by a sub-call that later
+// reverted instead of treating every recorded change as final.
+type StateChange struct {
+	Depth   int
+	Address thor.Address
+	Kind    StateChangeKind
+	Reason  BalanceChangeReason // meaningful only when Kind == StateChangeBalance
+	Slot    *thor.Bytes32       // meaningful only when Kind == StateChangeStorage
+	Prev    interface{}
+	New     interface{}
+}
+
+// StateChangeKind identifies which account field a StateChange describes.
+type StateChangeKind byte
+
+const (
+	StateChangeBalance StateChangeKind = iota + 1
+	StateChangeNonce
+	StateChangeCode
+	StateChangeStorage
+	StateChangeSuicide
+)
+
+// SetOnStateAccess installs hooks that fire for every state mutation made
+// by the VM's statedb (SubBalance, AddBalance, SetNonce, SetCode, SetState,
+// Suicide, AddLog), before the change is journaled, in addition to the
+// narrower SetOnTransfer/SetOnContractCreated callbacks.
+func (vm *VM) SetOnStateAccess(hooks StateAccessHooks) {
+	vm.statedb.SetStateAccessHooks(statedb.StateAccessHooks{
+		OnBalanceChange: func(addr common.Address, prev, new *big.Int, reason statedb.BalanceChangeReason) {
+			if hooks.OnBalanceChange != nil {
+				hooks.OnBalanceChange(thor.Address(addr), prev, new, BalanceChangeReason(reason))
+			}
+		},
+		OnNonceChange: func(addr common.Address, prev, new uint64) {
+			if hooks.OnNonceChange != nil {
+				hooks.OnNonceChange(thor.Address(addr), prev, new)
+			}
+		},
+		OnCodeChange: func(addr common.Address, prevHash common.Hash, prevCode []byte, newHash common.Hash, newCode []byte) {
+			if hooks.OnCodeChange != nil {
+				hooks.OnCodeChange(thor.Address(addr), thor.Bytes32(prevHash), prevCode, thor.Bytes32(newHash), newCode)
+			}
+		},
+		OnStorageChange: func(addr common.Address, slot, prev, new common.Hash) {
+			if hooks.OnStorageChange != nil {
+				hooks.OnStorageChange(thor.Address(addr), thor.Bytes32(slot), thor.Bytes32(prev), thor.Bytes32(new))
+			}
+		},
+		OnSuicide: func(addr, beneficiary common.Address, balance *big.Int) {
+			if hooks.OnSuicide != nil {
+				hooks.OnSuicide(thor.Address(addr), thor.Address(beneficiary), balance)
+			}
+		},
+		OnLog: func(log *types.Log) {
+			if hooks.OnLog != nil {
+				hooks.OnLog(ethlogToLog(log))
+			}
+		},
+		OnEnterFrame: func(depth int, caller, addr common.Address) {
+			if hooks.OnEnterFrame != nil {
+				hooks.OnEnterFrame(depth, thor.Address(caller), thor.Address(addr))
+			}
+		},
+		OnExitFrame: func(depth int, reverted bool) {
+			if hooks.OnExitFrame != nil {
+				hooks.OnExitFrame(depth, reverted)
+			}
+		},
+	})
+}
+
+// EnableStateChangeRecording turns on collection of Output.StateChanges.
+// It is off by default: recording batches every mutation of the executed
+// clause into memory for later inspection.
+//
+// This lives on VM rather than Config because Config mirrors evm.Config
+// verbatim; recording state changes is a Thor-side concern layered on top
+// of it.
+func (vm *VM) EnableStateChangeRecording(enable bool) {
+	vm.statedb.SetStateChangeRecording(enable)
+}
@@ -0,0 +1,208 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+)
+
+// fakeState is a minimal in-memory State for exercising VM in isolation
+// from Thor's real state.State.
+type fakeState struct {
+	balances map[thor.Address]*big.Int
+	nonces   map[thor.Address]uint64
+	code     map[thor.Address][]byte
+	storage  map[thor.Address]map[thor.Bytes32]thor.Bytes32
+}
+
+func newFakeState() *fakeState {
+	return &fakeState{
+		balances: make(map[thor.Address]*big.Int),
+		nonces:   make(map[thor.Address]uint64),
+		code:     make(map[thor.Address][]byte),
+		storage:  make(map[thor.Address]map[thor.Bytes32]thor.Bytes32),
+	}
+}
+
+func (s *fakeState) GetBalance(addr thor.Address) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+func (s *fakeState) SetBalance(addr thor.Address, balance *big.Int) { s.balances[addr] = balance }
+func (s *fakeState) GetEnergy(addr thor.Address, blockTime uint64) *big.Int {
+	return new(big.Int)
+}
+func (s *fakeState) SetEnergy(addr thor.Address, energy *big.Int, blockTime uint64) {}
+func (s *fakeState) GetStorage(addr thor.Address, key thor.Bytes32) thor.Bytes32 {
+	return s.storage[addr][key]
+}
+func (s *fakeState) SetStorage(addr thor.Address, key, value thor.Bytes32) {
+	if s.storage[addr] == nil {
+		s.storage[addr] = make(map[thor.Bytes32]thor.Bytes32)
+	}
+	s.storage[addr][key] = value
+}
+func (s *fakeState) GetCode(addr thor.Address) []byte           { return s.code[addr] }
+func (s *fakeState) SetCode(addr thor.Address, code []byte)     { s.code[addr] = code }
+func (s *fakeState) GetCodeHash(addr thor.Address) thor.Bytes32 { return thor.Bytes32{} }
+func (s *fakeState) GetNonce(addr thor.Address) uint64          { return s.nonces[addr] }
+func (s *fakeState) SetNonce(addr thor.Address, nonce uint64)   { s.nonces[addr] = nonce }
+func (s *fakeState) Exists(addr thor.Address) bool {
+	_, ok := s.balances[addr]
+	return ok
+}
+func (s *fakeState) Delete(addr thor.Address) { delete(s.balances, addr) }
+
+func newTestVM(state State) *VM {
+	return New(Context{
+		GetHash:  func(uint32) thor.Bytes32 { return thor.Bytes32{} },
+		GasPrice: new(big.Int),
+	}, state, Config{})
+}
+
+// stubPrecompile always succeeds without touching state itself; any state
+// change observed around it comes from the VM's own value-transfer step.
+type stubPrecompile struct{}
+
+func (p *stubPrecompile) RequiredGas(input []byte) uint64 { return 100 }
+func (p *stubPrecompile) Run(ctx PrecompileContext, input []byte) ([]byte, error) {
+	return nil, nil
+}
+
+// loggingPrecompile writes a storage slot and emits a log through its
+// PrecompileContext, so a test can assert both land on the same statedb
+// path a regular contract's SSTORE/LOG would use.
+type loggingPrecompile struct{}
+
+func (p *loggingPrecompile) RequiredGas(input []byte) uint64 { return 100 }
+func (p *loggingPrecompile) Run(ctx PrecompileContext, input []byte) ([]byte, error) {
+	ctx.State().SetStorage(ctx.Caller(), thor.Bytes32{1}, thor.Bytes32{2})
+	ctx.Log([]thor.Bytes32{{3}}, []byte("payload"))
+	return nil, nil
+}
+
+// callerRecordingPrecompile records the Caller() it is invoked with, so a
+// test can assert what address a re-entrant call observes as its caller.
+type callerRecordingPrecompile struct {
+	observedCaller thor.Address
+}
+
+func (p *callerRecordingPrecompile) RequiredGas(input []byte) uint64 { return 100 }
+func (p *callerRecordingPrecompile) Run(ctx PrecompileContext, input []byte) ([]byte, error) {
+	p.observedCaller = ctx.Caller()
+	return nil, nil
+}
+
+// reentrantPrecompile re-enters the VM via StaticCall, so a test can assert
+// the nested call's caller is the precompile's own address rather than
+// whoever invoked it.
+type reentrantPrecompile struct {
+	target thor.Address
+}
+
+func (p *reentrantPrecompile) RequiredGas(input []byte) uint64 { return 100 }
+func (p *reentrantPrecompile) Run(ctx PrecompileContext, input []byte) ([]byte, error) {
+	return ctx.StaticCall(p.target, input, 1000).Value, nil
+}
+
+func TestCallFiresEnterExitFrame(t *testing.T) {
+	vm := newTestVM(newFakeState())
+
+	var entered, exited []int
+	vm.SetOnStateAccess(StateAccessHooks{
+		OnEnterFrame: func(depth int, caller, addr thor.Address) { entered = append(entered, depth) },
+		OnExitFrame:  func(depth int, reverted bool) { exited = append(exited, depth) },
+	})
+
+	out := vm.Call(thor.Address{1}, thor.Address{2}, nil, 1000, new(big.Int))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, []int{1}, entered)
+	assert.Equal(t, []int{1}, exited)
+}
+
+func TestPrecompileDispatchFiresEnterExitFrame(t *testing.T) {
+	state := newFakeState()
+	caller := thor.Address{1}
+	state.SetBalance(caller, big.NewInt(100))
+
+	vm := newTestVM(state)
+	registry := NewPrecompileRegistry()
+	addr := thor.Address{9}
+	registry.Register(addr, &stubPrecompile{})
+	vm.SetPrecompileRegistry(registry)
+
+	var entered, exited []int
+	vm.SetOnStateAccess(StateAccessHooks{
+		OnEnterFrame: func(depth int, caller, a thor.Address) { entered = append(entered, depth) },
+		OnExitFrame:  func(depth int, reverted bool) { exited = append(exited, depth) },
+	})
+	vm.EnableStateChangeRecording(true)
+
+	out := vm.Call(caller, addr, nil, 1000, big.NewInt(10))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, []int{1}, entered, "precompile dispatch must bracket EnterFrame like a regular call")
+	assert.Equal(t, []int{1}, exited)
+
+	// the value transfer routed through runPrecompile produces two balance
+	// StateChanges, both at depth 1 since the precompile dispatch is itself
+	// the outermost frame.
+	if assert.Len(t, out.StateChanges, 2) {
+		for _, c := range out.StateChanges {
+			assert.Equal(t, 1, c.Depth)
+			assert.Equal(t, StateChangeBalance, c.Kind)
+		}
+	}
+}
+
+func TestPrecompileStateAndLogRouteThroughStatedb(t *testing.T) {
+	state := newFakeState()
+	caller := thor.Address{1}
+
+	vm := newTestVM(state)
+	registry := NewPrecompileRegistry()
+	addr := thor.Address{9}
+	registry.Register(addr, &loggingPrecompile{})
+	vm.SetPrecompileRegistry(registry)
+
+	var codeChanges, storageChanges int
+	vm.SetOnStateAccess(StateAccessHooks{
+		OnStorageChange: func(addr thor.Address, slot, prev, new thor.Bytes32) { storageChanges++ },
+		OnCodeChange: func(addr thor.Address, prevHash thor.Bytes32, prevCode []byte, newHash thor.Bytes32, newCode []byte) {
+			codeChanges++
+		},
+	})
+	vm.EnableStateChangeRecording(true)
+
+	out := vm.Call(caller, addr, nil, 1000, new(big.Int))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, 1, storageChanges, "a precompile's SetStorage must fire OnStorageChange like a regular SSTORE would")
+	assert.Equal(t, 0, codeChanges)
+	if assert.Len(t, out.StateChanges, 1) {
+		assert.Equal(t, StateChangeStorage, out.StateChanges[0].Kind)
+	}
+	if assert.Len(t, out.Logs, 1) {
+		assert.Equal(t, addr, out.Logs[0].Address, "a precompile's Log must be attributed to its own address")
+	}
+}
+
+func TestPrecompileReentryUsesOwnAddressAsCaller(t *testing.T) {
+	state := newFakeState()
+	caller := thor.Address{1}
+
+	vm := newTestVM(state)
+	registry := NewPrecompileRegistry()
+	outer := thor.Address{9}
+	inner := thor.Address{10}
+	recorder := &callerRecordingPrecompile{}
+	registry.Register(outer, &reentrantPrecompile{target: inner})
+	registry.Register(inner, recorder)
+	vm.SetPrecompileRegistry(registry)
+
+	out := vm.Call(caller, outer, nil, 1000, new(big.Int))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, outer, recorder.observedCaller, "a precompile's re-entrant call must report the precompile itself as caller, not its own caller")
+}
@@ -0,0 +1,365 @@
+// Package statedb adapts Thor's thor.Address-keyed account state to the
+// common.Address-keyed StateDB interface the EVM expects, and is the single
+// place every state mutation an executed clause makes actually happens.
+package statedb
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm/evm"
+)
+
+// BalanceChangeReason re-exports evm.BalanceChangeReason so StateAccessHooks
+// and StateChange don't need to import evm themselves to name it.
+type BalanceChangeReason = evm.BalanceChangeReason
+
+const (
+	BalanceChangeTransfer     = evm.BalanceChangeTransfer
+	BalanceChangeGasBuy       = evm.BalanceChangeGasBuy
+	BalanceChangeGasRefund    = evm.BalanceChangeGasRefund
+	BalanceChangeReward       = evm.BalanceChangeReward
+	BalanceChangeSelfdestruct = evm.BalanceChangeSelfdestruct
+)
+
+// StateAccessHooks is a full state-access tracer usable for live indexing:
+// every mutating StateDB method invokes the corresponding hook right after
+// the change is applied to state, with the value before and after it, in
+// application order, so a consumer can pair them with
+// OnEnterFrame/OnExitFrame to discard changes made by a reverted sub-call.
+type StateAccessHooks struct {
+	OnBalanceChange func(addr common.Address, prev, new *big.Int, reason BalanceChangeReason)
+	OnNonceChange   func(addr common.Address, prev, new uint64)
+	OnCodeChange    func(addr common.Address, prevHash common.Hash, prevCode []byte, newHash common.Hash, newCode []byte)
+	OnStorageChange func(addr common.Address, slot, prev, new common.Hash)
+	OnSuicide       func(addr, beneficiary common.Address, balance *big.Int)
+	OnLog           func(log *types.Log)
+
+	// OnEnterFrame/OnExitFrame bracket every EnterFrame/ExitFrame call, i.e.
+	// every Call/StaticCall/Create and every precompile dispatch, including
+	// the outermost one.
+	OnEnterFrame func(depth int, caller, addr common.Address)
+	OnExitFrame  func(depth int, reverted bool)
+}
+
+// StateChangeKind identifies which account field a StateChange describes.
+type StateChangeKind byte
+
+const (
+	StateChangeBalance StateChangeKind = iota + 1
+	StateChangeNonce
+	StateChangeCode
+	StateChangeStorage
+	StateChangeSuicide
+)
+
+// StateChange is one entry of a recorded state-change trace, produced when
+// SetStateChangeRecording(true) is in effect. Depth is the call depth the
+// change was made at (see StateDB.EnterFrame), so a consumer can discard
+// entries belonging to a sub-call that later reverted.
+type StateChange struct {
+	Depth   int
+	Address common.Address
+	Kind    StateChangeKind
+	Reason  BalanceChangeReason // meaningful only when Kind == StateChangeBalance
+	Slot    *common.Hash        // meaningful only when Kind == StateChangeStorage
+	Prev    interface{}
+	New     interface{}
+}
+
+// State is the thor.Address-keyed account state StateDB wraps. It is
+// implemented by Thor's real state.State; State exists here only to
+// decouple this package from it.
+type State interface {
+	GetBalance(addr thor.Address) *big.Int
+	SetBalance(addr thor.Address, balance *big.Int)
+	GetEnergy(addr thor.Address, blockTime uint64) *big.Int
+	SetEnergy(addr thor.Address, energy *big.Int, blockTime uint64)
+	GetStorage(addr thor.Address, key thor.Bytes32) thor.Bytes32
+	SetStorage(addr thor.Address, key, value thor.Bytes32)
+	GetCode(addr thor.Address) []byte
+	SetCode(addr thor.Address, code []byte)
+	GetCodeHash(addr thor.Address) thor.Bytes32
+	GetNonce(addr thor.Address) uint64
+	SetNonce(addr thor.Address, nonce uint64)
+	Exists(addr thor.Address) bool
+	Delete(addr thor.Address)
+}
+
+// StateDB is the facade evm.EVM mutates through.
+type StateDB struct {
+	state State
+
+	refund    uint64
+	logs      []*types.Log
+	preimages map[common.Hash][]byte
+	suicided  map[common.Address]bool
+
+	hooks         StateAccessHooks
+	depth         int
+	recordChanges bool
+	changes       []StateChange
+}
+
+// New returns a StateDB wrapping state.
+func New(state State) *StateDB {
+	return &StateDB{
+		state:     state,
+		preimages: make(map[common.Hash][]byte),
+		suicided:  make(map[common.Address]bool),
+	}
+}
+
+// State returns the underlying thor.Address-keyed state.
+func (s *StateDB) State() State {
+	return s.state
+}
+
+// SetStateAccessHooks installs hooks that fire for every state mutation
+// made through this StateDB, in addition to any recording enabled via
+// SetStateChangeRecording.
+func (s *StateDB) SetStateAccessHooks(hooks StateAccessHooks) {
+	s.hooks = hooks
+}
+
+// SetStateChangeRecording turns collection of GetStateChanges' trace on or
+// off. Disabling it discards anything recorded so far.
+func (s *StateDB) SetStateChangeRecording(enable bool) {
+	s.recordChanges = enable
+	if !enable {
+		s.changes = nil
+	}
+}
+
+// GetStateChanges drains the state changes recorded since the last call, or
+// returns nil if SetStateChangeRecording(true) was never called.
+func (s *StateDB) GetStateChanges() []StateChange {
+	changes := s.changes
+	s.changes = nil
+	return changes
+}
+
+// EnterFrame records descent into a new call frame rooted at addr, entered
+// from caller, firing StateAccessHooks.OnEnterFrame. It returns the new
+// depth, which every StateChange recorded until the matching ExitFrame
+// carries.
+func (s *StateDB) EnterFrame(caller, addr common.Address) int {
+	s.depth++
+	if s.hooks.OnEnterFrame != nil {
+		s.hooks.OnEnterFrame(s.depth, caller, addr)
+	}
+	return s.depth
+}
+
+// ExitFrame records return from the most recently entered frame not yet
+// exited, firing StateAccessHooks.OnExitFrame with whether it reverted.
+func (s *StateDB) ExitFrame(reverted bool) {
+	if s.hooks.OnExitFrame != nil {
+		s.hooks.OnExitFrame(s.depth, reverted)
+	}
+	s.depth--
+}
+
+// GetBalance returns addr's balance.
+func (s *StateDB) GetBalance(addr common.Address) *big.Int {
+	return s.state.GetBalance(thor.Address(addr))
+}
+
+// SubBalance subtracts amount from addr's balance.
+func (s *StateDB) SubBalance(addr common.Address, amount *big.Int, reason BalanceChangeReason) {
+	if amount.Sign() == 0 {
+		return
+	}
+	prev := s.state.GetBalance(thor.Address(addr))
+	next := new(big.Int).Sub(prev, amount)
+	s.state.SetBalance(thor.Address(addr), next)
+	s.recordBalanceChange(addr, prev, next, reason)
+}
+
+// AddBalance adds amount to addr's balance.
+func (s *StateDB) AddBalance(addr common.Address, amount *big.Int, reason BalanceChangeReason) {
+	if amount.Sign() == 0 {
+		return
+	}
+	prev := s.state.GetBalance(thor.Address(addr))
+	next := new(big.Int).Add(prev, amount)
+	s.state.SetBalance(thor.Address(addr), next)
+	s.recordBalanceChange(addr, prev, next, reason)
+}
+
+func (s *StateDB) recordBalanceChange(addr common.Address, prev, next *big.Int, reason BalanceChangeReason) {
+	if s.hooks.OnBalanceChange != nil {
+		s.hooks.OnBalanceChange(addr, prev, next, reason)
+	}
+	if s.recordChanges {
+		s.changes = append(s.changes, StateChange{
+			Depth: s.depth, Address: addr, Kind: StateChangeBalance, Reason: reason, Prev: prev, New: next,
+		})
+	}
+}
+
+// GetNonce returns addr's nonce.
+func (s *StateDB) GetNonce(addr common.Address) uint64 {
+	return s.state.GetNonce(thor.Address(addr))
+}
+
+// SetNonce sets addr's nonce.
+func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
+	prev := s.state.GetNonce(thor.Address(addr))
+	s.state.SetNonce(thor.Address(addr), nonce)
+
+	if s.hooks.OnNonceChange != nil {
+		s.hooks.OnNonceChange(addr, prev, nonce)
+	}
+	if s.recordChanges {
+		s.changes = append(s.changes, StateChange{
+			Depth: s.depth, Address: addr, Kind: StateChangeNonce, Prev: prev, New: nonce,
+		})
+	}
+}
+
+// GetCodeHash returns addr's code hash.
+func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
+	return common.Hash(s.state.GetCodeHash(thor.Address(addr)))
+}
+
+// GetCode returns addr's code.
+func (s *StateDB) GetCode(addr common.Address) []byte {
+	return s.state.GetCode(thor.Address(addr))
+}
+
+// GetCodeSize returns the length of addr's code.
+func (s *StateDB) GetCodeSize(addr common.Address) int {
+	return len(s.state.GetCode(thor.Address(addr)))
+}
+
+// SetCode sets addr's code.
+func (s *StateDB) SetCode(addr common.Address, code []byte) {
+	prevCode := s.state.GetCode(thor.Address(addr))
+	prevHash := common.Hash(s.state.GetCodeHash(thor.Address(addr)))
+	s.state.SetCode(thor.Address(addr), code)
+	newHash := common.Hash(s.state.GetCodeHash(thor.Address(addr)))
+
+	if s.hooks.OnCodeChange != nil {
+		s.hooks.OnCodeChange(addr, prevHash, prevCode, newHash, code)
+	}
+	if s.recordChanges {
+		s.changes = append(s.changes, StateChange{
+			Depth: s.depth, Address: addr, Kind: StateChangeCode, Prev: prevCode, New: code,
+		})
+	}
+}
+
+// GetState returns the value stored at slot in addr's storage.
+func (s *StateDB) GetState(addr common.Address, slot common.Hash) common.Hash {
+	return common.Hash(s.state.GetStorage(thor.Address(addr), thor.Bytes32(slot)))
+}
+
+// SetState sets the value stored at slot in addr's storage.
+func (s *StateDB) SetState(addr common.Address, slot, value common.Hash) {
+	prev := common.Hash(s.state.GetStorage(thor.Address(addr), thor.Bytes32(slot)))
+	s.state.SetStorage(thor.Address(addr), thor.Bytes32(slot), thor.Bytes32(value))
+
+	if s.hooks.OnStorageChange != nil {
+		s.hooks.OnStorageChange(addr, slot, prev, value)
+	}
+	if s.recordChanges {
+		sl := slot
+		s.changes = append(s.changes, StateChange{
+			Depth: s.depth, Address: addr, Kind: StateChangeStorage, Slot: &sl, Prev: prev, New: value,
+		})
+	}
+}
+
+// Exist reports whether addr has any state associated with it.
+func (s *StateDB) Exist(addr common.Address) bool {
+	return s.state.Exists(thor.Address(addr))
+}
+
+// Empty reports whether addr is empty per EIP-161 (no balance, no nonce, no code).
+func (s *StateDB) Empty(addr common.Address) bool {
+	a := thor.Address(addr)
+	return s.state.GetBalance(a).Sign() == 0 && s.state.GetNonce(a) == 0 && len(s.state.GetCode(a)) == 0
+}
+
+// Suicide destroys addr, paying its balance to beneficiary.
+func (s *StateDB) Suicide(addr, beneficiary common.Address) bool {
+	balance := s.state.GetBalance(thor.Address(addr))
+	if beneficiary != addr && balance.Sign() != 0 {
+		s.AddBalance(beneficiary, balance, BalanceChangeSelfdestruct)
+	}
+	s.state.SetBalance(thor.Address(addr), new(big.Int))
+	s.state.Delete(thor.Address(addr))
+	s.suicided[addr] = true
+
+	if s.hooks.OnSuicide != nil {
+		s.hooks.OnSuicide(addr, beneficiary, balance)
+	}
+	if s.recordChanges {
+		s.changes = append(s.changes, StateChange{
+			Depth: s.depth, Address: addr, Kind: StateChangeSuicide, Prev: balance, New: beneficiary,
+		})
+	}
+	return true
+}
+
+// HasSuicided reports whether addr was destroyed during this execution.
+func (s *StateDB) HasSuicided(addr common.Address) bool {
+	return s.suicided[addr]
+}
+
+// AddRefund increases the pending gas refund.
+func (s *StateDB) AddRefund(gas uint64) {
+	s.refund += gas
+}
+
+// SubRefund decreases the pending gas refund.
+func (s *StateDB) SubRefund(gas uint64) {
+	if gas > s.refund {
+		s.refund = 0
+		return
+	}
+	s.refund -= gas
+}
+
+// GetRefund returns the pending gas refund.
+func (s *StateDB) GetRefund() uint64 {
+	return s.refund
+}
+
+// AddLog records a log emitted by the LOG opcode.
+func (s *StateDB) AddLog(log *types.Log) {
+	s.logs = append(s.logs, log)
+	if s.hooks.OnLog != nil {
+		s.hooks.OnLog(log)
+	}
+}
+
+// AddPreimage records the preimage of a SHA3 operation.
+func (s *StateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	if _, ok := s.preimages[hash]; !ok {
+		cpy := append([]byte(nil), preimage...)
+		s.preimages[hash] = cpy
+	}
+}
+
+// GetOutputs passes the logs and preimages recorded during execution to
+// logFn/preimageFn, one at a time, until one returns false. It does not
+// clear them, so a later call sees them again.
+func (s *StateDB) GetOutputs(
+	logFn func(log *types.Log) bool,
+	preimageFn func(hash common.Hash, preimage []byte) bool,
+) {
+	for _, log := range s.logs {
+		if !logFn(log) {
+			break
+		}
+	}
+	for hash, preimage := range s.preimages {
+		if !preimageFn(hash, preimage) {
+			break
+		}
+	}
+}
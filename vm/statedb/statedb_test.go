@@ -0,0 +1,111 @@
+package statedb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+)
+
+// fakeState is a minimal in-memory State for exercising StateDB in
+// isolation from Thor's real state.State.
+type fakeState struct {
+	balances map[thor.Address]*big.Int
+	nonces   map[thor.Address]uint64
+	code     map[thor.Address][]byte
+	storage  map[thor.Address]map[thor.Bytes32]thor.Bytes32
+}
+
+func newFakeState() *fakeState {
+	return &fakeState{
+		balances: make(map[thor.Address]*big.Int),
+		nonces:   make(map[thor.Address]uint64),
+		code:     make(map[thor.Address][]byte),
+		storage:  make(map[thor.Address]map[thor.Bytes32]thor.Bytes32),
+	}
+}
+
+func (s *fakeState) GetBalance(addr thor.Address) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+func (s *fakeState) SetBalance(addr thor.Address, balance *big.Int) { s.balances[addr] = balance }
+func (s *fakeState) GetEnergy(addr thor.Address, blockTime uint64) *big.Int {
+	return new(big.Int)
+}
+func (s *fakeState) SetEnergy(addr thor.Address, energy *big.Int, blockTime uint64) {}
+func (s *fakeState) GetStorage(addr thor.Address, key thor.Bytes32) thor.Bytes32 {
+	return s.storage[addr][key]
+}
+func (s *fakeState) SetStorage(addr thor.Address, key, value thor.Bytes32) {
+	if s.storage[addr] == nil {
+		s.storage[addr] = make(map[thor.Bytes32]thor.Bytes32)
+	}
+	s.storage[addr][key] = value
+}
+func (s *fakeState) GetCode(addr thor.Address) []byte       { return s.code[addr] }
+func (s *fakeState) SetCode(addr thor.Address, code []byte) { s.code[addr] = code }
+func (s *fakeState) GetCodeHash(addr thor.Address) thor.Bytes32 {
+	return thor.Bytes32{}
+}
+func (s *fakeState) GetNonce(addr thor.Address) uint64        { return s.nonces[addr] }
+func (s *fakeState) SetNonce(addr thor.Address, nonce uint64) { s.nonces[addr] = nonce }
+func (s *fakeState) Exists(addr thor.Address) bool {
+	_, ok := s.balances[addr]
+	return ok
+}
+func (s *fakeState) Delete(addr thor.Address) { delete(s.balances, addr) }
+
+func TestEnterExitFrameTracksDepth(t *testing.T) {
+	sdb := New(newFakeState())
+
+	var entered, exited []int
+	sdb.SetStateAccessHooks(StateAccessHooks{
+		OnEnterFrame: func(depth int, caller, addr common.Address) { entered = append(entered, depth) },
+		OnExitFrame:  func(depth int, reverted bool) { exited = append(exited, depth) },
+	})
+
+	d1 := sdb.EnterFrame(common.Address{}, common.Address{1})
+	d2 := sdb.EnterFrame(common.Address{1}, common.Address{2})
+	sdb.ExitFrame(false)
+	sdb.ExitFrame(true)
+
+	assert.Equal(t, 1, d1)
+	assert.Equal(t, 2, d2)
+	assert.Equal(t, []int{1, 2}, entered)
+	assert.Equal(t, []int{2, 1}, exited)
+}
+
+func TestStateChangeRecordingCarriesDepth(t *testing.T) {
+	sdb := New(newFakeState())
+	sdb.SetStateChangeRecording(true)
+
+	addr := common.Address{1}
+	sdb.EnterFrame(common.Address{}, addr)
+	sdb.AddBalance(addr, big.NewInt(10), BalanceChangeTransfer)
+	sdb.EnterFrame(addr, common.Address{2})
+	sdb.SetNonce(common.Address{2}, 1)
+	sdb.ExitFrame(false)
+	sdb.ExitFrame(false)
+
+	changes := sdb.GetStateChanges()
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, 1, changes[0].Depth)
+		assert.Equal(t, StateChangeBalance, changes[0].Kind)
+		assert.Equal(t, 2, changes[1].Depth)
+		assert.Equal(t, StateChangeNonce, changes[1].Kind)
+	}
+
+	// draining resets the buffer
+	assert.Empty(t, sdb.GetStateChanges())
+}
+
+func TestStateChangeRecordingOffByDefault(t *testing.T) {
+	sdb := New(newFakeState())
+	sdb.AddBalance(common.Address{1}, big.NewInt(1), BalanceChangeTransfer)
+	assert.Empty(t, sdb.GetStateChanges())
+}
@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm/evm"
+)
+
+// OpCode identifies the kind of call reported to a Tracer's CaptureEnter.
+type OpCode = evm.OpCode
+
+const (
+	CALL         = evm.CALL
+	CALLCODE     = evm.CALLCODE
+	DELEGATECALL = evm.DELEGATECALL
+	STATICCALL   = evm.STATICCALL
+	CREATE       = evm.CREATE
+	CREATE2      = evm.CREATE2
+)
+
+// ScopeContext exposes the interpreter state around the opcode currently
+// being executed, for a Tracer to inspect during CaptureState. It is an
+// alias of evm.ScopeContext, like OpCode above, so that tracerAdapter's
+// CaptureState/CaptureFault satisfy evm.Tracer without a conversion step.
+type ScopeContext = evm.ScopeContext
+
+// Tracer receives call-frame-level callbacks during execution, shaped like
+// go-ethereum's vm.EVMLogger. It is attached via VM.SetTracer and invoked by
+// the underlying evm.EVM at the corresponding points in
+// Call/StaticCall/Create. CaptureState/CaptureFault are part of the
+// interface, but this tree has no opcode interpreter, so they are never
+// invoked (see evm.Tracer).
+type Tracer interface {
+	// CaptureStart is called once at the beginning of the outermost call.
+	CaptureStart(from, to thor.Address, create bool, input []byte, gas uint64, value *big.Int)
+	// CaptureEnd is called once when the outermost call returns.
+	CaptureEnd(output []byte, gasUsed uint64, err error)
+	// CaptureEnter is called at the start of each nested call (CALL,
+	// DELEGATECALL, STATICCALL, CALLCODE, CREATE, CREATE2).
+	CaptureEnter(typ OpCode, from, to thor.Address, input []byte, gas uint64, value *big.Int)
+	// CaptureExit is called when a nested call returns.
+	CaptureExit(output []byte, gasUsed uint64, err error)
+	// CaptureState reports an opcode about to execute. Never invoked in this
+	// tree; see the type doc comment.
+	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error)
+	// CaptureFault reports an opcode that failed to complete. Never invoked
+	// in this tree; see the type doc comment.
+	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
+}
+
+// SetTracer attaches t so it receives call-frame-level callbacks for every
+// subsequent Call/StaticCall/Create, including precompile dispatch (which
+// bypasses evm.EVM entirely, see VM.runPrecompile). Passing nil detaches any
+// previously set tracer.
+//
+// This lives on VM as a setter rather than a Config field for the same
+// reason EnableStateChangeRecording does: Config mirrors evm.Config
+// verbatim, so attachment goes through the facade instead.
+func (vm *VM) SetTracer(t Tracer) {
+	vm.tracer = t
+	if t == nil {
+		vm.evm.SetTracer(nil)
+		return
+	}
+	vm.evm.SetTracer(&tracerAdapter{t: t})
+}
+
+// tracerAdapter bridges the thor.Address-based vm.Tracer contract to the
+// common.Address-based evm.Tracer that evm.EVM actually invokes, the same
+// way vm.go bridges OnTransfer and StateAccessHooks.
+type tracerAdapter struct {
+	t Tracer
+}
+
+func (a *tracerAdapter) CaptureStart(from, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	a.t.CaptureStart(thor.Address(from), thor.Address(to), create, input, gas, value)
+}
+
+func (a *tracerAdapter) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	a.t.CaptureEnd(output, gasUsed, err)
+}
+
+func (a *tracerAdapter) CaptureEnter(typ OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) {
+	a.t.CaptureEnter(typ, thor.Address(from), thor.Address(to), input, gas, value)
+}
+
+func (a *tracerAdapter) CaptureExit(output []byte, gasUsed uint64, err error) {
+	a.t.CaptureExit(output, gasUsed, err)
+}
+
+func (a *tracerAdapter) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
+	a.t.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+}
+
+func (a *tracerAdapter) CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error) {
+	a.t.CaptureFault(pc, op, gas, cost, scope, depth, err)
+}
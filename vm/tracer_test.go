@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+)
+
+// fakeTracer records every call it receives, in order, for assertion.
+type fakeTracer struct {
+	starts int
+	ends   int
+	enters []OpCode
+	exits  int
+	states int
+	faults int
+}
+
+func (f *fakeTracer) CaptureStart(_, _ thor.Address, _ bool, _ []byte, _ uint64, _ *big.Int) {
+	f.starts++
+}
+func (f *fakeTracer) CaptureEnd([]byte, uint64, error) { f.ends++ }
+func (f *fakeTracer) CaptureEnter(typ OpCode, _, _ thor.Address, _ []byte, _ uint64, _ *big.Int) {
+	f.enters = append(f.enters, typ)
+}
+func (f *fakeTracer) CaptureExit([]byte, uint64, error) { f.exits++ }
+func (f *fakeTracer) CaptureState(uint64, OpCode, uint64, uint64, *ScopeContext, []byte, int, error) {
+	f.states++
+}
+func (f *fakeTracer) CaptureFault(uint64, OpCode, uint64, uint64, *ScopeContext, int, error) {
+	f.faults++
+}
+
+func TestCallFiresCaptureStartAndEnd(t *testing.T) {
+	vm := newTestVM(newFakeState())
+
+	tracer := &fakeTracer{}
+	vm.SetTracer(tracer)
+
+	out := vm.Call(thor.Address{1}, thor.Address{2}, nil, 1000, new(big.Int))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, 1, tracer.starts)
+	assert.Equal(t, 1, tracer.ends)
+	assert.Empty(t, tracer.enters, "outermost call must not also fire CaptureEnter")
+	assert.Equal(t, 0, tracer.exits)
+	assert.Equal(t, 0, tracer.states, "CaptureState is never invoked in this tree")
+	assert.Equal(t, 0, tracer.faults, "CaptureFault is never invoked in this tree")
+}
+
+func TestPrecompileDispatchFiresCaptureStartAndEnd(t *testing.T) {
+	state := newFakeState()
+	caller := thor.Address{1}
+	state.SetBalance(caller, big.NewInt(100))
+
+	vm := newTestVM(state)
+	registry := NewPrecompileRegistry()
+	addr := thor.Address{9}
+	registry.Register(addr, &stubPrecompile{})
+	vm.SetPrecompileRegistry(registry)
+
+	tracer := &fakeTracer{}
+	vm.SetTracer(tracer)
+
+	out := vm.Call(caller, addr, nil, 1000, big.NewInt(10))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, 1, tracer.starts, "precompile dispatch must fire CaptureStart like a regular call")
+	assert.Equal(t, 1, tracer.ends)
+	assert.Empty(t, tracer.enters)
+	assert.Equal(t, 0, tracer.exits)
+}
+
+func TestSetTracerNilDetaches(t *testing.T) {
+	vm := newTestVM(newFakeState())
+
+	tracer := &fakeTracer{}
+	vm.SetTracer(tracer)
+	vm.SetTracer(nil)
+
+	out := vm.Call(thor.Address{1}, thor.Address{2}, nil, 1000, new(big.Int))
+	assert.Nil(t, out.VMErr)
+	assert.Equal(t, 0, tracer.starts, "detached tracer must receive no further callbacks")
+}
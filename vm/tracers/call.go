@@ -0,0 +1,110 @@
+// Package tracers provides ready-to-use vm.Tracer implementations built on
+// the call-frame and opcode callbacks in package vm.
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm"
+)
+
+// CallFrame is one node of the call tree emitted by CallTracer.
+type CallFrame struct {
+	Type    string       `json:"type"`
+	From    thor.Address `json:"from"`
+	To      thor.Address `json:"to"`
+	Value   *big.Int     `json:"value,omitempty"`
+	Gas     uint64       `json:"gas"`
+	GasUsed uint64       `json:"gasUsed"`
+	Input   []byte       `json:"input,omitempty"`
+	Output  []byte       `json:"output,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Calls   []*CallFrame `json:"calls,omitempty"`
+}
+
+// CallTracer builds a nested JSON call tree of every CALL/CREATE performed
+// during execution.
+type CallTracer struct {
+	root  *CallFrame
+	stack []*CallFrame
+}
+
+// NewCallTracer returns a fresh CallTracer, ready to attach via
+// vm.VM.SetTracer.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// CaptureStart implements vm.Tracer.
+func (c *CallTracer) CaptureStart(from, to thor.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	typ := "CALL"
+	if create {
+		typ = "CREATE"
+	}
+	c.root = &CallFrame{
+		Type:  typ,
+		From:  from,
+		To:    to,
+		Value: value,
+		Gas:   gas,
+		Input: input,
+	}
+	c.stack = []*CallFrame{c.root}
+}
+
+// CaptureEnd implements vm.Tracer.
+func (c *CallTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if c.root == nil {
+		return
+	}
+	c.root.Output = output
+	c.root.GasUsed = gasUsed
+	if err != nil {
+		c.root.Error = err.Error()
+	}
+}
+
+// CaptureEnter implements vm.Tracer.
+func (c *CallTracer) CaptureEnter(typ vm.OpCode, from, to thor.Address, input []byte, gas uint64, value *big.Int) {
+	frame := &CallFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    to,
+		Value: value,
+		Gas:   gas,
+		Input: input,
+	}
+	parent := c.stack[len(c.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+	c.stack = append(c.stack, frame)
+}
+
+// CaptureExit implements vm.Tracer.
+func (c *CallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(c.stack) == 0 {
+		return
+	}
+	frame := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	frame.Output = output
+	frame.GasUsed = gasUsed
+	if err != nil {
+		frame.Error = err.Error()
+	}
+}
+
+// CaptureState implements vm.Tracer. CallTracer only cares about call
+// frames, so opcode-level events are ignored.
+func (c *CallTracer) CaptureState(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, []byte, int, error) {
+}
+
+// CaptureFault implements vm.Tracer.
+func (c *CallTracer) CaptureFault(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
+
+// Result returns the completed call tree as JSON.
+func (c *CallTracer) Result() ([]byte, error) {
+	return json.Marshal(c.root)
+}
@@ -0,0 +1,67 @@
+package tracers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm"
+)
+
+// FourByteTracer tallies how often each function selector is invoked, and
+// with what calldata size, across every CALL-family frame of an execution.
+type FourByteTracer struct {
+	counts map[string]int
+}
+
+// NewFourByteTracer returns a fresh FourByteTracer.
+func NewFourByteTracer() *FourByteTracer {
+	return &FourByteTracer{counts: make(map[string]int)}
+}
+
+// CaptureStart implements vm.Tracer.
+func (f *FourByteTracer) CaptureStart(_, _ thor.Address, create bool, input []byte, _ uint64, _ *big.Int) {
+	if create {
+		return
+	}
+	f.tally(input)
+}
+
+// CaptureEnd implements vm.Tracer.
+func (f *FourByteTracer) CaptureEnd([]byte, uint64, error) {}
+
+// CaptureEnter implements vm.Tracer. Every nested call frame is tallied by
+// its 4-byte selector and calldata size, mirroring go-ethereum's
+// 4byteTracer.
+func (f *FourByteTracer) CaptureEnter(typ vm.OpCode, _, _ thor.Address, input []byte, _ uint64, _ *big.Int) {
+	if typ == vm.CREATE || typ == vm.CREATE2 {
+		return
+	}
+	f.tally(input)
+}
+
+// CaptureExit implements vm.Tracer.
+func (f *FourByteTracer) CaptureExit([]byte, uint64, error) {}
+
+// CaptureState implements vm.Tracer.
+func (f *FourByteTracer) CaptureState(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, []byte, int, error) {
+}
+
+// CaptureFault implements vm.Tracer.
+func (f *FourByteTracer) CaptureFault(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
+
+func (f *FourByteTracer) tally(input []byte) {
+	if len(input) < 4 {
+		return
+	}
+	selector := binary.BigEndian.Uint32(input[:4])
+	key := fmt.Sprintf("%08x-%d", selector, len(input))
+	f.counts[key]++
+}
+
+// Result returns the tally, keyed by "<selector>-<calldatasize>".
+func (f *FourByteTracer) Result() map[string]int {
+	return f.counts
+}
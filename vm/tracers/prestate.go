@@ -0,0 +1,87 @@
+package tracers
+
+import (
+	"math/big"
+
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm"
+)
+
+// AccountState is the pre-execution snapshot of a single touched account,
+// as recorded by PrestateTracer.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    []byte
+	Storage map[thor.Bytes32]thor.Bytes32
+}
+
+// PrestateTracer records the state of every account touched during
+// execution, as it was immediately before the first touch. Unlike
+// CallTracer/FourByteTracer it does not attach via vm.VM.SetTracer;
+// instead its Hooks() are installed with vm.VM.SetOnStateAccess, since the
+// prestate snapshot needs the prev values statedb's access hooks already
+// carry.
+type PrestateTracer struct {
+	accounts map[thor.Address]*AccountState
+}
+
+// NewPrestateTracer returns a fresh PrestateTracer.
+func NewPrestateTracer() *PrestateTracer {
+	return &PrestateTracer{accounts: make(map[thor.Address]*AccountState)}
+}
+
+// Hooks returns the StateAccessHooks that feed this tracer. Install them
+// with vm.SetOnStateAccess(tracer.Hooks()) before executing.
+func (p *PrestateTracer) Hooks() vm.StateAccessHooks {
+	return vm.StateAccessHooks{
+		OnBalanceChange: func(addr thor.Address, prev, _ *big.Int, _ vm.BalanceChangeReason) {
+			if acc := p.account(addr); acc.Balance == nil {
+				acc.Balance = prev
+			}
+		},
+		OnNonceChange: func(addr thor.Address, prev, _ uint64) {
+			if acc := p.account(addr); acc.Nonce == nil {
+				acc.Nonce = &prev
+			}
+		},
+		OnCodeChange: func(addr thor.Address, _ thor.Bytes32, prevCode []byte, _ thor.Bytes32, _ []byte) {
+			if acc := p.account(addr); acc.Code == nil {
+				// non-nil even when prevCode is empty, so a later change
+				// does not overwrite the first recorded value.
+				acc.Code = append([]byte{}, prevCode...)
+			}
+		},
+		OnStorageChange: func(addr thor.Address, slot, prev, _ thor.Bytes32) {
+			acc := p.account(addr)
+			if acc.Storage == nil {
+				acc.Storage = make(map[thor.Bytes32]thor.Bytes32)
+			}
+			if _, ok := acc.Storage[slot]; !ok {
+				acc.Storage[slot] = prev
+			}
+		},
+		OnSuicide: func(addr, _ thor.Address, balance *big.Int) {
+			if acc := p.account(addr); acc.Balance == nil {
+				acc.Balance = balance
+			}
+		},
+	}
+}
+
+// account returns the AccountState for addr, recording it as touched on
+// first access and leaving already-recorded fields untouched so only the
+// value immediately before the very first touch is kept.
+func (p *PrestateTracer) account(addr thor.Address) *AccountState {
+	acc, ok := p.accounts[addr]
+	if !ok {
+		acc = &AccountState{}
+		p.accounts[addr] = acc
+	}
+	return acc
+}
+
+// Result returns the accumulated prestate snapshot.
+func (p *PrestateTracer) Result() map[thor.Address]*AccountState {
+	return p.accounts
+}
@@ -0,0 +1,90 @@
+package tracers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vechain/thor/thor"
+	"github.com/vechain/thor/vm"
+)
+
+func TestCallTracerBuildsNestedTree(t *testing.T) {
+	c := NewCallTracer()
+
+	from, to := thor.Address{1}, thor.Address{2}
+	c.CaptureStart(from, to, false, []byte{0x01}, 1000, big.NewInt(5))
+
+	inner := thor.Address{3}
+	c.CaptureEnter(vm.CALL, to, inner, []byte{0x02}, 500, new(big.Int))
+	c.CaptureExit([]byte("ok"), 100, nil)
+
+	c.CaptureEnd([]byte("done"), 400, nil)
+
+	out, err := c.Result()
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"type":"CALL"`)
+	assert.Contains(t, string(out), `"gasUsed":400`)
+
+	assert.Equal(t, to, c.root.Calls[0].From)
+	assert.Equal(t, inner, c.root.Calls[0].To)
+	assert.Equal(t, uint64(100), c.root.Calls[0].GasUsed)
+}
+
+func TestCallTracerRecordsError(t *testing.T) {
+	c := NewCallTracer()
+	c.CaptureStart(thor.Address{1}, thor.Address{2}, true, nil, 1000, new(big.Int))
+	c.CaptureEnd(nil, 1000, assert.AnError)
+
+	assert.Equal(t, assert.AnError.Error(), c.root.Error)
+	assert.Equal(t, "CREATE", c.root.Type)
+}
+
+func TestFourByteTracerTalliesSelectors(t *testing.T) {
+	f := NewFourByteTracer()
+
+	input := []byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02}
+	f.CaptureStart(thor.Address{1}, thor.Address{2}, false, input, 1000, new(big.Int))
+	f.CaptureEnter(vm.CALL, thor.Address{2}, thor.Address{3}, input, 500, new(big.Int))
+
+	result := f.Result()
+	assert.Equal(t, 2, result["deadbeef-6"])
+}
+
+func TestFourByteTracerIgnoresShortInput(t *testing.T) {
+	f := NewFourByteTracer()
+	f.CaptureStart(thor.Address{1}, thor.Address{2}, false, []byte{0x01, 0x02}, 1000, new(big.Int))
+
+	assert.Empty(t, f.Result())
+}
+
+func TestPrestateTracerKeepsFirstTouchOnly(t *testing.T) {
+	p := NewPrestateTracer()
+	hooks := p.Hooks()
+
+	addr := thor.Address{1}
+	hooks.OnBalanceChange(addr, big.NewInt(10), big.NewInt(20), vm.BalanceChangeTransfer)
+	hooks.OnBalanceChange(addr, big.NewInt(20), big.NewInt(30), vm.BalanceChangeTransfer)
+
+	result := p.Result()
+	if assert.Contains(t, result, addr) {
+		assert.Equal(t, big.NewInt(10), result[addr].Balance, "only the value before the very first touch is kept")
+	}
+}
+
+func TestPrestateTracerRecordsNonceCodeAndStorage(t *testing.T) {
+	p := NewPrestateTracer()
+	hooks := p.Hooks()
+
+	addr := thor.Address{1}
+	hooks.OnNonceChange(addr, 1, 2)
+	hooks.OnCodeChange(addr, thor.Bytes32{}, []byte("old"), thor.Bytes32{}, []byte("new"))
+	hooks.OnStorageChange(addr, thor.Bytes32{9}, thor.Bytes32{1}, thor.Bytes32{2})
+
+	acc := p.Result()[addr]
+	if assert.NotNil(t, acc) {
+		assert.Equal(t, uint64(1), *acc.Nonce)
+		assert.Equal(t, []byte("old"), acc.Code)
+		assert.Equal(t, thor.Bytes32{1}, acc.Storage[thor.Bytes32{9}])
+	}
+}
@@ -21,8 +21,9 @@ type Output struct {
 	LeftOverGas     uint64
 	RefundGas       uint64
 	Preimages       map[thor.Bytes32][]byte
-	VMErr           error         // VMErr identify the execution result of the contract function, not evm function's err.
-	ContractAddress *thor.Address // if create a new contract, or is nil.
+	VMErr           error          // VMErr identify the execution result of the contract function, not evm function's err.
+	ContractAddress *thor.Address  // if create a new contract, or is nil.
+	StateChanges    []*StateChange // populated only when EnableStateChangeRecording(true) was called.
 }
 
 // Log represents a contract log event. These events are generated by the LOG opcode and
@@ -50,9 +51,12 @@ type OnTransfer func(sender, recipient thor.Address, amount *big.Int)
 
 // VM is a facade for ethEvm.
 type VM struct {
-	evm        *evm.EVM
-	statedb    *statedb.StateDB
-	onTransfer OnTransfer
+	evm         *evm.EVM
+	statedb     *statedb.StateDB
+	onTransfer  OnTransfer
+	ctx         Context
+	precompiles *PrecompileRegistry
+	tracer      Tracer
 }
 
 var chainConfig = &params.ChainConfig{
@@ -71,6 +75,7 @@ var chainConfig = &params.ChainConfig{
 
 // Context for VM runtime.
 type Context struct {
+	ChainTag    byte
 	Origin      thor.Address
 	Beneficiary thor.Address
 	BlockNumber uint32
@@ -89,15 +94,15 @@ func canTransfer(db evm.StateDB, addr common.Address, amount *big.Int) bool {
 
 // The only purpose of this func separate definition is to be compatible with evm.Context.
 func transfer(db evm.StateDB, sender, recipient common.Address, amount *big.Int) {
-	db.SubBalance(sender, amount)
-	db.AddBalance(recipient, amount)
+	db.SubBalance(sender, amount, evm.BalanceChangeTransfer)
+	db.AddBalance(recipient, amount, evm.BalanceChangeTransfer)
 }
 
 // New retutrns a new EVM . The returned EVM is not thread safe and should
 // only ever be used *once*.
 func New(ctx Context, state State, vmConfig Config) *VM {
 	statedb := statedb.New(state)
-	vm := &VM{statedb: statedb}
+	vm := &VM{statedb: statedb, ctx: ctx}
 	evmCtx := evm.Context{
 		CanTransfer: canTransfer,
 		Transfer: func(db evm.StateDB, sender, recipient common.Address, amount *big.Int) {
@@ -140,6 +145,13 @@ func (vm *VM) SetOnTransfer(cb OnTransfer) {
 	vm.onTransfer = cb
 }
 
+// SetPrecompileRegistry installs the set of stateful precompiles consulted
+// before regular contract execution in Call/StaticCall. Passing nil disables
+// precompile dispatch.
+func (vm *VM) SetPrecompileRegistry(registry *PrecompileRegistry) {
+	vm.precompiles = registry
+}
+
 // Cancel cancels any running EVM operation.
 // This may be called concurrently and it's safe to be called multiple times.
 func (vm *VM) Cancel() {
@@ -150,9 +162,12 @@ func (vm *VM) Cancel() {
 // It also handles any necessary value transfer required and takes the necessary steps to
 // create accounts and reverses the state in case of an execution error or failed value transfer.
 func (vm *VM) Call(caller thor.Address, addr thor.Address, input []byte, gas uint64, value *big.Int) *Output {
+	if out, handled := vm.runPrecompile(caller, addr, input, gas, value, false); handled {
+		return out
+	}
 	ret, leftOverGas, vmErr := vm.evm.Call(&vmContractRef{caller}, common.Address(addr), input, gas, value)
 	logs, preimages := vm.extractStateDBOutputs()
-	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, nil}
+	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, nil, vm.extractStateChanges()}
 }
 
 // StaticCall executes the contract associated with the addr with the given input as parameters
@@ -161,9 +176,117 @@ func (vm *VM) Call(caller thor.Address, addr thor.Address, input []byte, gas uin
 // Opcodes that attempt to perform such modifications will result in exceptions instead of performing
 // the modifications.
 func (vm *VM) StaticCall(caller thor.Address, addr thor.Address, input []byte, gas uint64) *Output {
+	if out, handled := vm.runPrecompile(caller, addr, input, gas, new(big.Int), true); handled {
+		return out
+	}
 	ret, leftOverGas, vmErr := vm.evm.StaticCall(&vmContractRef{caller}, common.Address(addr), input, gas)
 	logs, preimages := vm.extractStateDBOutputs()
-	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, nil}
+	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, nil, vm.extractStateChanges()}
+}
+
+// runPrecompile dispatches to a registered Precompile at addr, if any. The
+// bool return reports whether addr was handled by the registry, in which
+// case the *Output should be returned as-is by the caller.
+//
+// Dispatch is bracketed with EnterFrame/ExitFrame exactly like EVM.Call/
+// Create, even though it never goes through evm.EVM, so hook/trace
+// consumers see a precompile call as a frame like any other: Output.
+// StateChanges entries it produces carry the right depth, and a Tracer
+// attached via SetTracer sees a CaptureStart/CaptureEnd pair (or
+// CaptureEnter/CaptureExit, if nested inside another call) instead of
+// nothing at all.
+func (vm *VM) runPrecompile(caller thor.Address, addr thor.Address, input []byte, gas uint64, value *big.Int, readOnly bool) (out *Output, handled bool) {
+	if vm.precompiles == nil {
+		return nil, false
+	}
+	p, ok := vm.precompiles.Get(addr)
+	if !ok {
+		return nil, false
+	}
+	handled = true
+
+	from, to := common.Address(caller), common.Address(addr)
+	depth := vm.statedb.EnterFrame(from, to)
+	if vm.tracer != nil {
+		if depth == 1 {
+			vm.tracer.CaptureStart(caller, addr, false, input, gas, value)
+		} else {
+			typ := CALL
+			if readOnly {
+				typ = STATICCALL
+			}
+			vm.tracer.CaptureEnter(typ, caller, addr, input, gas, value)
+		}
+	}
+
+	var callErr error
+	leftOverGas := gas
+	var ret []byte
+	defer func() {
+		vm.statedb.ExitFrame(callErr != nil)
+		if vm.tracer != nil {
+			gasUsed := gas - leftOverGas
+			if depth == 1 {
+				vm.tracer.CaptureEnd(ret, gasUsed, callErr)
+			} else {
+				vm.tracer.CaptureExit(ret, gasUsed, callErr)
+			}
+		}
+	}()
+
+	requiredGas := p.RequiredGas(input)
+	if requiredGas > gas {
+		callErr = evm.ErrOutOfGas
+		out = &Output{VMErr: callErr}
+		return
+	}
+
+	if !readOnly && value != nil && value.Sign() != 0 {
+		if !canTransfer(vm.statedb, from, value) {
+			callErr = evm.ErrInsufficientBalance
+			out = &Output{VMErr: callErr, LeftOverGas: gas}
+			return
+		}
+		if vm.onTransfer != nil {
+			vm.onTransfer(caller, addr, value)
+		}
+		transfer(vm.statedb, from, to, value)
+	}
+	leftOverGas = gas - requiredGas
+
+	pctx := &precompileContext{
+		vm:       vm,
+		ctx:      vm.ctx,
+		self:     addr,
+		caller:   caller,
+		state:    newHookedState(vm.statedb.State(), vm.statedb),
+		readOnly: readOnly,
+		gas:      &leftOverGas,
+	}
+	var err error
+	ret, err = vm.runPrecompileCode(pctx, p, input)
+	callErr = err
+	logs, preimages := vm.extractStateDBOutputs()
+	out = &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, err, nil, vm.extractStateChanges()}
+	return
+}
+
+// runPrecompileCode invokes p.Run, converting a panic carrying an error
+// (such as ErrPrecompileReadOnly, raised by readOnlyState's mutating
+// methods when a precompile writes to its State() directly rather than
+// through Call) into a returned error instead of letting it crash the
+// caller.
+func (vm *VM) runPrecompileCode(ctx PrecompileContext, p Precompile, input []byte) (ret []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			panic(r)
+		}
+	}()
+	return p.Run(ctx, input)
 }
 
 // Create creates a new contract using code as deployment code.
@@ -171,7 +294,7 @@ func (vm *VM) Create(caller thor.Address, code []byte, gas uint64, value *big.In
 	ret, contractAddr, leftOverGas, vmErr := vm.evm.Create(&vmContractRef{caller}, code, gas, value)
 	contractAddress := thor.Address(contractAddr)
 	logs, preimages := vm.extractStateDBOutputs()
-	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, &contractAddress}
+	return &Output{ret, logs, leftOverGas, vm.statedb.GetRefund(), preimages, vmErr, &contractAddress, vm.extractStateChanges()}
 }
 
 // ChainConfig returns the evmironment's chain configuration
@@ -200,6 +323,31 @@ func (vm *VM) extractStateDBOutputs() (
 	return
 }
 
+// extractStateChanges drains the recorded state changes since the last
+// call, or returns nil if EnableStateChangeRecording was never turned on.
+func (vm *VM) extractStateChanges() []*StateChange {
+	changes := vm.statedb.GetStateChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+	out := make([]*StateChange, len(changes))
+	for i, c := range changes {
+		out[i] = &StateChange{
+			Depth:   c.Depth,
+			Address: thor.Address(c.Address),
+			Kind:    StateChangeKind(c.Kind),
+			Reason:  BalanceChangeReason(c.Reason),
+			Prev:    c.Prev,
+			New:     c.New,
+		}
+		if c.Slot != nil {
+			slot := thor.Bytes32(*c.Slot)
+			out[i].Slot = &slot
+		}
+	}
+	return out
+}
+
 func ethlogToLog(ethlog *types.Log) *Log {
 	var topics []thor.Bytes32
 	if len(ethlog.Topics) > 0 {